@@ -0,0 +1,98 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/usekiln/kiln/pkg/reporter"
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+func handleBaseline(args []string) {
+	if len(args) == 0 {
+		printBaselineHelp()
+		os.Exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "create":
+	case "--help", "-h", "help":
+		printBaselineHelp()
+		return
+	default:
+		fmt.Printf("❌ Unknown baseline command: %s\n\n", args[0])
+		printBaselineHelp()
+		os.Exit(exitUsage)
+	}
+
+	opts, ok := parseScanFlags(args[1:], printBaselineHelp)
+	if !ok {
+		return
+	}
+	// -o names the baseline rather than a report
+	output := opts.outputFile
+	if output == "" {
+		output = scanner.BaselineFile
+	}
+	opts.outputFile = ""
+	// Snapshot everything, not just what an older baseline left over
+	opts.baselineFile = ""
+
+	result := runScan(opts)
+	baseline := scanner.NewBaseline(result)
+
+	file, err := os.Create(output)
+	if err != nil {
+		fmt.Printf("❌ Error creating file: %v\n", err)
+		os.Exit(exitError)
+	}
+	if err := reporter.WriteBaselineJSON(file, baseline); err != nil {
+		file.Close()
+		fmt.Printf("❌ Error writing baseline: %v\n", err)
+		os.Exit(exitError)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Printf("❌ Error writing baseline: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	if opts.quiet {
+		return
+	}
+	fmt.Printf("✅ Baseline of %d findings saved to: %s\n", len(baseline.Findings), output)
+	if len(result.Errors) > 0 {
+		fmt.Printf("⚠️  %d files or blocks could not be parsed; their findings are not in the baseline\n", len(result.Errors))
+	}
+	fmt.Printf("   Scan against it with: kiln scan <path> --baseline %s\n", output)
+}
+
+func printBaselineHelp() {
+	fmt.Println("USAGE:")
+	fmt.Println("  kiln baseline create <path> [options]")
+	fmt.Println()
+	fmt.Println("DESCRIPTION:")
+	fmt.Println("  Snapshot the current violations and warnings by fingerprint. Scanning")
+	fmt.Println("  with --baseline then reports those findings separately and only fails")
+	fmt.Println("  on new ones, so kiln can gate CI on an estate with existing findings.")
+	fmt.Println("  Commit the baseline and regenerate it as findings are fixed.")
+	fmt.Println()
+	fmt.Println("OPTIONS:")
+	fmt.Println("  -o, --output <file>      Baseline file to write")
+	fmt.Println("                           Default: kiln-baseline.json")
+	fmt.Println()
+	fmt.Println("  Scan options such as --framework, --policy, --var-file, --plan and")
+	fmt.Println("  --exceptions are accepted as by kiln scan; use the same ones when")
+	fmt.Println("  scanning against the baseline so fingerprints line up.")
+	fmt.Println()
+	fmt.Println("  -h, --help               Show this help message")
+	fmt.Println()
+	fmt.Println("EXAMPLES:")
+	fmt.Println("  # Record the existing findings of a repository")
+	fmt.Println("  kiln baseline create terraform/")
+	fmt.Println()
+	fmt.Println("  # Fail CI only on findings added since")
+	fmt.Println("  kiln scan terraform/ --baseline kiln-baseline.json")
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+func handleConfig(args []string) {
+	if len(args) == 0 {
+		printConfigHelp()
+		os.Exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "validate":
+	case "--help", "-h", "help":
+		printConfigHelp()
+		return
+	default:
+		fmt.Printf("❌ Unknown config command: %s\n\n", args[0])
+		printConfigHelp()
+		os.Exit(exitUsage)
+	}
+
+	// Parse flags
+	path := ""
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--help", "-h":
+			printConfigHelp()
+			return
+		default:
+			if strings.HasPrefix(arg, "-") {
+				unknownFlag(arg, printConfigHelp)
+			}
+			path = arg
+		}
+	}
+
+	// A directory, or nothing, is where to start looking for .kiln.yaml
+	if info, err := os.Stat(path); path == "" || (err == nil && info.IsDir()) {
+		start := path
+		if start == "" {
+			start = "."
+		}
+		if path = scanner.FindConfig(start); path == "" {
+			fmt.Printf("❌ No %s found in %s or its parent directories\n", scanner.ConfigFile, start)
+			os.Exit(exitUsage)
+		}
+	}
+
+	config, err := scanner.LoadConfig(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	// Check what the configuration refers to
+	var problems, warnings []string
+	for _, dir := range config.Policies {
+		if _, err := os.Stat(dir); err != nil {
+			problems = append(problems, fmt.Sprintf("policies: %v", err))
+		}
+	}
+	checks, err := scanner.LoadChecks(scanner.Options{PolicyPaths: config.Policies})
+	if err != nil && len(problems) == 0 {
+		problems = append(problems, fmt.Sprintf("policies: %v", err))
+	}
+	if config.Exceptions != "" {
+		if _, err := scanner.LoadExceptions(config.Exceptions); err != nil {
+			problems = append(problems, fmt.Sprintf("exceptions: %v", err))
+		}
+	}
+
+	// Check IDs that match nothing are most likely typos
+	if checks != nil {
+		for _, pattern := range config.UnmatchedChecks(checks) {
+			warnings = append(warnings, fmt.Sprintf("check %s matches no check ID", pattern))
+		}
+	}
+
+	for _, warning := range warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("❌ %s\n", problem)
+		}
+		fmt.Printf("\n%s is invalid\n", path)
+		os.Exit(exitUsage)
+	}
+	fmt.Printf("✅ %s is valid\n", path)
+}
+
+func printConfigHelp() {
+	fmt.Println("USAGE:")
+	fmt.Println("  kiln config validate [path]")
+	fmt.Println()
+	fmt.Println("DESCRIPTION:")
+	fmt.Println("  Check a .kiln.yaml project configuration: unknown keys, invalid values,")
+	fmt.Println("  missing policy directories or exceptions file, and check IDs that match")
+	fmt.Println("  no check. <path> is the file, or a directory to look for .kiln.yaml in")
+	fmt.Println("  and above; default: the current directory.")
+	fmt.Println()
+	fmt.Println("  kiln scan reads .kiln.yaml from the scanned directory or a parent, up to")
+	fmt.Println("  the repository root. Flags given to kiln scan override its settings.")
+	fmt.Println()
+	fmt.Println("EXAMPLE .kiln.yaml:")
+	fmt.Println("  frameworks: [soc2, hipaa]")
+	fmt.Println("  policies: [compliance/policies]")
+	fmt.Println("  checks:")
+	fmt.Println("    disabled: [KILN-S3-006]")
+	fmt.Println("  severity:")
+	fmt.Println("    KILN-S3-003: high")
+	fmt.Println("  fail_on: high")
+	fmt.Println("  min_score: 70")
+	fmt.Println("  exclude: [examples/**]")
+	fmt.Println("  required_tags: [Environment, Owner, CostCenter]")
+	fmt.Println("  outputs:")
+	fmt.Println("    - format: cli")
+	fmt.Println("    - format: html")
+	fmt.Println("      file: kiln-report.html")
+	fmt.Println("  exceptions: kiln-exceptions.yaml")
+	fmt.Println()
+	fmt.Println("OPTIONS:")
+	fmt.Println("  -h, --help               Show this help message")
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/usekiln/kiln/pkg/reporter"
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+func handleControls(args []string) {
+	if len(args) == 0 {
+		printControlsHelp()
+		os.Exit(exitUsage)
+	}
+
+	action := args[0]
+	switch action {
+	case "list", "show":
+	case "--help", "-h", "help":
+		printControlsHelp()
+		return
+	default:
+		fmt.Printf("❌ Unknown controls command: %s\n\n", action)
+		printControlsHelp()
+		os.Exit(exitUsage)
+	}
+
+	// Parse flags
+	format := "cli"
+	var ids, policyDirs, frameworks []string
+
+	args = args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--format", "-f":
+			format = flagValue(args, &i, printControlsHelp)
+		case "--framework":
+			frameworks = append(frameworks, strings.Split(flagValue(args, &i, printControlsHelp), ",")...)
+		case "--policy", "--policy-dir":
+			policyDirs = append(policyDirs, flagValue(args, &i, printControlsHelp))
+		case "--help", "-h":
+			printControlsHelp()
+			return
+		default:
+			if strings.HasPrefix(arg, "-") {
+				unknownFlag(arg, printControlsHelp)
+			}
+			ids = append(ids, arg)
+		}
+	}
+
+	if format != "cli" && format != "text" && format != "json" {
+		fmt.Printf("❌ Unknown format: %s\n", format)
+		fmt.Println("   Supported formats: cli, json")
+		os.Exit(exitUsage)
+	}
+
+	catalog, err := scanner.LoadCatalog(scanner.Options{PolicyPaths: policyDirs})
+	if err != nil {
+		fmt.Printf("❌ Error loading control catalog: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	// Keep the selected frameworks
+	if len(frameworks) > 0 {
+		selected := &scanner.Catalog{}
+		for _, id := range frameworks {
+			controls, err := catalog.Framework(id)
+			if err != nil {
+				fmt.Printf("❌ Error: %v\n", err)
+				os.Exit(exitUsage)
+			}
+			selected.Controls = append(selected.Controls, controls...)
+		}
+		catalog = selected
+	}
+
+	if action == "show" {
+		if len(ids) == 0 {
+			fmt.Println("❌ Error: no control specified")
+			fmt.Println("   Example: kiln controls show CC6.1")
+			os.Exit(exitUsage)
+		}
+
+		found := &scanner.Catalog{}
+		for _, id := range ids {
+			controls := catalog.Find(id)
+			if len(controls) == 0 {
+				fmt.Printf("❌ Unknown control: %s\n", id)
+				fmt.Println("   Run 'kiln controls list' for the supported controls")
+				os.Exit(exitUsage)
+			}
+			found.Controls = append(found.Controls, controls...)
+		}
+		catalog = found
+	}
+
+	switch {
+	case format == "json":
+		err = reporter.WriteCatalogJSON(os.Stdout, catalog)
+	case action == "show":
+		err = reporter.WriteControlsCLI(os.Stdout, catalog.Controls)
+	default:
+		err = reporter.WriteCatalogCLI(os.Stdout, catalog)
+	}
+	if err != nil {
+		fmt.Printf("❌ Error writing catalog: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+func printControlsHelp() {
+	fmt.Println("USAGE:")
+	fmt.Println("  kiln controls list [options]")
+	fmt.Println("  kiln controls show <control>... [options]")
+	fmt.Println()
+	fmt.Println("DESCRIPTION:")
+	fmt.Println("  List the controls of every framework, or show what a control requires")
+	fmt.Println("  and which checks and resource types provide evidence for it. Built from")
+	fmt.Println("  the checks' annotations, so auditors see which checks back which criteria.")
+	fmt.Println()
+	fmt.Println("OPTIONS:")
+	fmt.Println("  --framework <id>         Only list controls of a framework (can be")
+	fmt.Println("                           repeated or comma-separated)")
+	fmt.Println("                           soc2, iso27001, hipaa, pci, cis-aws")
+	fmt.Println()
+	fmt.Println("  -f, --format <format>    Output format (cli, json)")
+	fmt.Println("                           Default: cli")
+	fmt.Println()
+	fmt.Println("  --policy <dir>           Include the checks, crosswalk.yaml and")
+	fmt.Println("                           controls.yaml of a policy directory")
+	fmt.Println("                           (can be repeated)")
+	fmt.Println()
+	fmt.Println("  -h, --help               Show this help message")
+	fmt.Println()
+	fmt.Println("EXAMPLES:")
+	fmt.Println("  # Every supported control with its number of checks")
+	fmt.Println("  kiln controls list")
+	fmt.Println()
+	fmt.Println("  # The checks backing a SOC2 criterion")
+	fmt.Println("  kiln controls show CC6.1")
+	fmt.Println()
+	fmt.Println("  # CIS AWS 3.1 only, not other frameworks' control 3.1")
+	fmt.Println("  kiln controls show 3.1 --framework cis-aws")
+	fmt.Println()
+	fmt.Println("  # Machine-readable catalog for the audit evidence folder")
+	fmt.Println("  kiln controls list --format json > controls.json")
+}
@@ -5,7 +5,9 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/usekiln/kiln/pkg/reporter"
@@ -14,10 +16,18 @@ import (
 
 const version = "0.1.0"
 
+// Exit codes, so that CI can tell findings from a broken setup
+const (
+	exitClean     = 0 // nothing breached the thresholds
+	exitThreshold = 1 // violations at the fail-on severity, or a score below min-score
+	exitUsage     = 2 // invalid arguments or configuration
+	exitError     = 3 // files or policies could not be parsed or evaluated
+)
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	command := os.Args[1]
@@ -26,9 +36,21 @@ func main() {
 	case "scan":
 		if len(os.Args) < 3 {
 			printScanHelp()
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
 		handleScan(os.Args[2:])
+	case "drift":
+		if len(os.Args) < 3 {
+			printDriftHelp()
+			os.Exit(exitUsage)
+		}
+		handleDrift(os.Args[2:])
+	case "controls":
+		handleControls(os.Args[2:])
+	case "baseline":
+		handleBaseline(os.Args[2:])
+	case "config":
+		handleConfig(os.Args[2:])
 	case "version", "-v", "--version":
 		fmt.Printf("kiln v%s\n", version)
 	case "help", "-h", "--help":
@@ -40,90 +62,523 @@ func main() {
 	default:
 		fmt.Printf("❌ Unknown command: %s\n\n", command)
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
 
-func handleScan(args []string) {
-	// Parse flags
-	format := "cli"
-	outputFile := ""
-	quiet := false
+// scanOptions are the flags of kiln scan, shared with kiln baseline create,
+// completed from .kiln.yaml by applyConfig
+type scanOptions struct {
+	format         string
+	outputFile     string
+	planFile       string
+	stateFile      string
+	pivot          string
+	exceptionsFile string
+	baselineFile   string
+	configFile     string
+	failOn         string
+	minScore       *int
+	quiet          bool
+	parseOpts      scanner.ParseOptions
+
+	paths, policyDirs, entrypoints, frameworks []string
 
-	var paths []string
+	// Set by .kiln.yaml only
+	config *scanner.Config
+}
+
+// parseScanFlags parses the flags of kiln scan. It returns false when help
+// was printed instead.
+func parseScanFlags(args []string, printHelp func()) (scanOptions, bool) {
+	opts := scanOptions{
+		parseOpts: scanner.ParseOptions{Vars: make(map[string]string)},
+	}
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
+		value := func() string { return flagValue(args, &i, printHelp) }
 
 		switch arg {
 		case "--format", "-f":
-			if i+1 < len(args) {
-				format = args[i+1]
-				i++
-			}
+			opts.format = value()
 		case "--output", "-o":
-			if i+1 < len(args) {
-				outputFile = args[i+1]
-				i++
+			opts.outputFile = value()
+		case "--plan":
+			opts.planFile = value()
+		case "--state":
+			opts.stateFile = value()
+		case "--policy", "--policy-dir":
+			opts.policyDirs = append(opts.policyDirs, value())
+		case "--framework":
+			opts.frameworks = append(opts.frameworks, strings.Split(value(), ",")...)
+		case "--pivot":
+			opts.pivot = value()
+		case "--entrypoint":
+			opts.entrypoints = append(opts.entrypoints, value())
+		case "--exceptions":
+			opts.exceptionsFile = value()
+		case "--baseline":
+			opts.baselineFile = value()
+		case "--config":
+			opts.configFile = value()
+		case "--fail-on":
+			failOn := value()
+			opts.failOn = strings.ToLower(failOn)
+			if !scanner.ValidSeverity(opts.failOn) {
+				fmt.Printf("❌ Invalid --fail-on %q: expected critical, high, medium or low\n", failOn)
+				os.Exit(exitUsage)
 			}
+		case "--min-score":
+			minScore := value()
+			score, err := strconv.Atoi(minScore)
+			if err != nil || score < 0 || score > 100 {
+				fmt.Printf("❌ Invalid --min-score %q: expected a score from 0 to 100\n", minScore)
+				os.Exit(exitUsage)
+			}
+			opts.minScore = &score
+		case "--var-file":
+			opts.parseOpts.VarFiles = append(opts.parseOpts.VarFiles, value())
+		case "--var":
+			variable := value()
+			name, val, ok := strings.Cut(variable, "=")
+			if !ok {
+				fmt.Printf("❌ Invalid --var %q: expected name=value\n", variable)
+				os.Exit(exitUsage)
+			}
+			opts.parseOpts.Vars[name] = val
 		case "--quiet", "-q":
-			quiet = true
+			opts.quiet = true
 		case "--help", "-h":
-			printScanHelp()
-			return
+			printHelp()
+			return opts, false
 		default:
-			if !strings.HasPrefix(arg, "-") {
-				paths = append(paths, arg)
+			if strings.HasPrefix(arg, "-") {
+				unknownFlag(arg, printHelp)
 			}
+			opts.paths = append(opts.paths, arg)
 		}
 	}
 
-	if len(paths) == 0 {
+	if len(opts.paths) == 0 && opts.planFile == "" && opts.stateFile == "" {
 		fmt.Println("❌ Error: no path specified")
 		fmt.Println()
-		printScanHelp()
-		os.Exit(1)
+		printHelp()
+		os.Exit(exitUsage)
+	}
+	for _, path := range append(append([]string{}, opts.paths...), opts.planFile, opts.stateFile) {
+		if _, err := os.Stat(path); path != "" && err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+	for _, id := range append(append([]string{}, opts.frameworks...), opts.pivot) {
+		if _, err := scanner.LookupFramework(id); id != "" && err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	applyConfig(&opts)
+	for _, dir := range opts.policyDirs {
+		if _, err := os.Stat(dir); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	return opts, true
+}
+
+// flagValue returns the value following the flag at args[*i] and advances
+// past it, exiting with a usage error when the flag comes last
+func flagValue(args []string, i *int, printHelp func()) string {
+	if *i+1 >= len(args) {
+		fmt.Printf("❌ Missing value for %s\n\n", args[*i])
+		printHelp()
+		os.Exit(exitUsage)
+	}
+	*i++
+	return args[*i]
+}
+
+// unknownFlag exits with a usage error for an option a command doesn't take
+func unknownFlag(arg string, printHelp func()) {
+	fmt.Printf("❌ Unknown option: %s\n\n", arg)
+	printHelp()
+	os.Exit(exitUsage)
+}
+
+// applyConfig loads .kiln.yaml, from --config or found next to what is
+// scanned, and fills in what the flags leave unset
+func applyConfig(opts *scanOptions) {
+	path := opts.configFile
+	if path == "" {
+		path = scanner.FindConfig(scanRoot(*opts))
+	}
+	if path == "" {
+		opts.config = &scanner.Config{}
+		return
+	}
+
+	config, err := scanner.LoadConfig(path)
+	if err != nil {
+		fmt.Printf("❌ Error loading config: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	opts.config = config
+
+	if len(opts.frameworks) == 0 {
+		opts.frameworks = config.Frameworks
+	}
+	if len(opts.policyDirs) == 0 {
+		opts.policyDirs = config.Policies
+	}
+	if opts.exceptionsFile == "" {
+		opts.exceptionsFile = config.Exceptions
+	}
+	if opts.failOn == "" {
+		opts.failOn = config.FailOn
+	}
+	if opts.minScore == nil {
+		opts.minScore = config.MinScore
+	}
+}
+
+// scanRoot is where configuration files are looked for: the first path
+// scanned, or the current directory for plans and state files
+func scanRoot(opts scanOptions) string {
+	if len(opts.paths) > 0 {
+		return opts.paths[0]
+	}
+	return "."
+}
+
+// outputs are the reports to write: the one selected by --format and
+// --output, else those of .kiln.yaml, else the terminal report
+func (opts scanOptions) outputs() []scanner.OutputConfig {
+	if opts.format != "" || opts.outputFile != "" {
+		format := opts.format
+		if format == "" {
+			format = "cli"
+		}
+		return []scanner.OutputConfig{{Format: format, File: opts.outputFile}}
+	}
+	if len(opts.config.Outputs) > 0 {
+		return opts.config.Outputs
+	}
+	return []scanner.OutputConfig{{Format: "cli"}}
+}
+
+// terminalOutput reports whether one of the outputs is the terminal report
+// on stdout
+func terminalOutput(outputs []scanner.OutputConfig) bool {
+	for _, o := range outputs {
+		if (o.Format == "cli" || o.Format == "text") && o.File == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// newScanner creates the scanner opts configure, exiting on errors
+func newScanner(opts scanOptions) *scanner.Scanner {
+	// Pivoting needs the framework's checks in the scan
+	frameworks := opts.frameworks
+	if opts.pivot != "" && !containsFold(frameworks, opts.pivot) {
+		frameworks = append(frameworks, opts.pivot)
+	}
+
+	// Apply the repository's exceptions file, found next to what is scanned
+	// or in a parent directory unless given
+	exceptionsFile := opts.exceptionsFile
+	if exceptionsFile == "" {
+		exceptionsFile = scanner.FindExceptionsFile(scanRoot(opts))
+	}
+	var exceptions []scanner.Exception
+	if exceptionsFile != "" {
+		var err error
+		if exceptions, err = scanner.LoadExceptions(exceptionsFile); err != nil {
+			fmt.Printf("❌ Error loading exceptions: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	var baseline *scanner.Baseline
+	if opts.baselineFile != "" {
+		var err error
+		if baseline, err = scanner.LoadBaseline(opts.baselineFile); err != nil {
+			fmt.Printf("❌ Error loading baseline: %v\n", err)
+			os.Exit(exitUsage)
+		}
 	}
 
 	// Initialize scanner
-	s, err := scanner.New([]string{"policies/soc2"})
+	s, err := scanner.NewWithOptions(scanner.Options{
+		Frameworks:     frameworks,
+		PolicyPaths:    opts.policyDirs,
+		Entrypoints:    opts.entrypoints,
+		EnabledChecks:  opts.config.Checks.Enabled,
+		DisabledChecks: opts.config.Checks.Disabled,
+		Severities:     opts.config.Severity,
+		RequiredTags:   opts.config.RequiredTags,
+		Exclude:        opts.config.Exclude,
+		Parse:          opts.parseOpts,
+		Exceptions:     exceptions,
+		Baseline:       baseline,
+		Progress:       progressPrinter(terminalOutput(opts.outputs()), opts.quiet),
+	})
 	if err != nil {
 		fmt.Printf("❌ Error initializing scanner: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+	return s
+}
+
+// checkVars exits with a usage error when --var sets a variable that the
+// configuration at paths doesn't declare, e.g. a mistyped name
+func checkVars(s *scanner.Scanner, opts scanOptions, paths []string) {
+	if len(opts.parseOpts.Vars) == 0 || len(paths) == 0 {
+		return
+	}
+
+	names, err := s.UndeclaredVars(paths)
+	if err != nil {
+		fmt.Printf("❌ Scan failed: %v\n", err)
+		os.Exit(exitError)
+	}
+	for _, name := range names {
+		fmt.Printf("❌ Invalid --var %s: undeclared variable %q\n", name, name)
+	}
+	if len(names) > 0 {
+		os.Exit(exitUsage)
+	}
+}
+
+// runScan scans what opts select, exiting on errors
+func runScan(opts scanOptions) *scanner.Result {
+	s := newScanner(opts)
+	if opts.planFile == "" && opts.stateFile == "" {
+		checkVars(s, opts, opts.paths)
 	}
 
-	// Scan
 	var result *scanner.Result
-	if len(paths) == 1 {
-		result, err = s.ScanPath(paths[0])
+	var err error
+	if opts.planFile != "" {
+		result, err = s.ScanPlan(opts.planFile)
+	} else if opts.stateFile != "" {
+		result, err = s.ScanState(opts.stateFile)
+	} else if len(opts.paths) == 1 {
+		result, err = s.ScanPath(opts.paths[0])
 	} else {
-		result, err = s.ScanFiles(paths)
+		result, err = s.ScanFiles(opts.paths)
 	}
 
 	if err != nil {
 		fmt.Printf("❌ Scan failed: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+
+	return result
+}
+
+func handleScan(args []string) {
+	opts, ok := parseScanFlags(args, printScanHelp)
+	if !ok {
+		return
+	}
+
+	reportResult(runScan(opts), opts)
+}
+
+// reportResult writes the outputs opts select, pivoted to one framework's
+// controls with --pivot, and exits with the code the result calls for.
+// Pivoting only changes the reports: thresholds are checked on the whole
+// result, so findings the framework doesn't map still fail the scan.
+func reportResult(result *scanner.Result, opts scanOptions) {
+	reported := result
+	if opts.pivot != "" {
+		var err error
+		if reported, err = result.Pivot(opts.pivot); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	outputs := opts.outputs()
+	for _, o := range outputs {
+		writeResult(reported, o.Format, o.File, opts.quiet)
+	}
+	warnExceptions(result, terminalOutput(outputs), opts.quiet)
+
+	// Exit with an error code if new violations at the fail-on severity are
+	// found or the score is below min-score, else if files could not be
+	// scanned, which makes the result incomplete; baselined findings don't
+	// fail the scan. A breached threshold takes precedence so that CI can
+	// tell failing policies from a broken scan.
+	failed, reasons := checkThresholds(result, opts.failOn, opts.minScore)
+	for _, reason := range reasons {
+		fmt.Fprintf(os.Stderr, "❌ %s\n", reason)
+	}
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "❌ %d files or blocks could not be scanned\n", len(result.Errors))
+	}
+	if failed {
+		os.Exit(exitThreshold)
+	}
+	if len(result.Errors) > 0 {
+		os.Exit(exitError)
+	}
+}
+
+// checkThresholds reports whether result fails the thresholds: a violation
+// of failOn severity or above (any violation when failOn is empty), or a
+// score below minScore when set. reasons explain the configured thresholds
+// that failed.
+func checkThresholds(result *scanner.Result, failOn string, minScore *int) (failed bool, reasons []string) {
+	failing := 0
+	for _, v := range result.Violations {
+		if failOn == "" || scanner.SeverityAtLeast(v.Severity, failOn) {
+			failing++
+		}
+	}
+	if failing > 0 {
+		failed = true
+		if failOn != "" {
+			reasons = append(reasons, fmt.Sprintf("%d violations at %s severity or above", failing, failOn))
+		}
+	}
+
+	if minScore != nil && result.Score < *minScore {
+		failed = true
+		reasons = append(reasons, fmt.Sprintf("score %d is below the minimum of %d", result.Score, *minScore))
+	}
+	return failed, reasons
+}
+
+func handleDrift(args []string) {
+	opts, ok := parseScanFlags(args, printDriftHelp)
+	if !ok {
+		return
+	}
+	if len(opts.paths) != 1 || opts.stateFile == "" || opts.planFile != "" {
+		fmt.Println("❌ Error: drift needs one configuration path and --state")
+		fmt.Println()
+		printDriftHelp()
+		os.Exit(exitUsage)
+	}
+	if len(opts.entrypoints) > 0 {
+		fmt.Println("❌ Error: --entrypoint does not apply to drift, which runs no Rego rules")
+		fmt.Println()
+		printDriftHelp()
+		os.Exit(exitUsage)
 	}
 
-	// Output results based on format
+	s := newScanner(opts)
+	checkVars(s, opts, opts.paths)
+	result, err := s.Drift(opts.paths[0], opts.stateFile)
+	if err != nil {
+		fmt.Printf("❌ Drift check failed: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	// Security-relevant drift fails like violations of a scan
+	reportResult(result, opts)
+}
+
+// writeResult writes results in the requested format to outputFile, or to
+// stdout when no file is given
+func writeResult(result *scanner.Result, format, outputFile string, quiet bool) {
+	var write func(io.Writer, *scanner.Result) error
 	switch format {
 	case "json":
-		reporter.PrintJSON(result, outputFile)
+		write = reporter.WriteJSON
 	case "html":
-		reporter.PrintHTML(result, outputFile)
+		if outputFile == "" {
+			fmt.Println("❌ Error: --output flag is required for HTML format")
+			fmt.Println("   Example: kiln scan main.tf --format html --output report.html")
+			os.Exit(exitUsage)
+		}
+		write = reporter.WriteHTML
 	case "cli", "text":
-		if !quiet {
-			reporter.PrintCLI(result)
+		if quiet {
+			return
 		}
+		write = reporter.WriteCLI
 	default:
 		fmt.Printf("❌ Unknown format: %s\n", format)
 		fmt.Println("   Supported formats: cli, json, html")
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+
+	if outputFile == "" {
+		if err := write(os.Stdout, result); err != nil {
+			fmt.Printf("❌ Error writing report: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("❌ Error creating file: %v\n", err)
+		os.Exit(exitError)
+	}
+	if err := write(file, result); err != nil {
+		file.Close()
+		fmt.Printf("❌ Error writing report: %v\n", err)
+		os.Exit(exitError)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Printf("❌ Error writing report: %v\n", err)
+		os.Exit(exitError)
 	}
 
-	// Exit with error code if violations found
-	if len(result.Violations) > 0 {
-		os.Exit(1)
+	// On stderr, as another output may be writing a report to stdout
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "✅ Report saved to: %s\n", outputFile)
+	}
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// warnExceptions prints the exceptions needing review to stderr when no
+// terminal report, which lists them already, is written
+func warnExceptions(result *scanner.Result, terminal, quiet bool) {
+	if quiet || terminal {
+		return
+	}
+	for _, e := range result.Exceptions {
+		if warning := e.Warning(); warning != "" {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+		}
+	}
+}
+
+// progressPrinter returns a progress callback that announces what is being
+// scanned, for terminal output only so JSON on stdout stays parseable
+func progressPrinter(terminal, quiet bool) scanner.ProgressFunc {
+	if quiet || !terminal {
+		return nil
+	}
+
+	return func(p scanner.Progress) {
+		if p.Stage != scanner.ProgressDiscovered || p.Files < 2 {
+			return
+		}
+		if p.Path == "" {
+			fmt.Printf("📁 Scanning %d Terraform files\n\n", p.Files)
+		} else {
+			fmt.Printf("📁 Scanning %d Terraform files in %s\n\n", p.Files, p.Path)
+		}
 	}
 }
 
@@ -131,6 +586,14 @@ func handleHelpCommand(topic string) {
 	switch topic {
 	case "scan":
 		printScanHelp()
+	case "drift":
+		printDriftHelp()
+	case "controls":
+		printControlsHelp()
+	case "baseline":
+		printBaselineHelp()
+	case "config":
+		printConfigHelp()
 	default:
 		fmt.Printf("No help available for: %s\n\n", topic)
 		printUsage()
@@ -141,13 +604,18 @@ func printUsage() {
 	fmt.Println("🔥 Kiln - SOC2 Trust Service Criteria Scanner for Terraform")
 	fmt.Println()
 	fmt.Println("Scan your infrastructure code for SOC2 Trust Service Criteria violations")
-	fmt.Println("and get actionable remediation guidance.")
+	fmt.Println("(or ISO 27001, HIPAA, PCI DSS and CIS AWS with --framework) and get")
+	fmt.Println("actionable remediation guidance.")
 	fmt.Println()
 	fmt.Println("USAGE:")
 	fmt.Println("  kiln <command> [options]")
 	fmt.Println()
 	fmt.Println("COMMANDS:")
 	fmt.Println("  scan         Scan Terraform files for compliance issues")
+	fmt.Println("  drift        Compare configuration with a state file for out-of-band changes")
+	fmt.Println("  controls     List supported controls and the checks implementing them")
+	fmt.Println("  baseline     Record existing findings so scans fail only on new ones")
+	fmt.Println("  config       Validate the .kiln.yaml project configuration")
 	fmt.Println("  version      Show version information")
 	fmt.Println("  help         Show help for a command")
 	fmt.Println()
@@ -164,6 +632,25 @@ func printUsage() {
 	fmt.Println("  # Export results as JSON")
 	fmt.Println("  kiln scan main.tf --format json --output report.json")
 	fmt.Println()
+	fmt.Println("  # Scan a Terraform plan (terraform show -json plan.out > plan.json)")
+	fmt.Println("  kiln scan --plan plan.json")
+	fmt.Println()
+	fmt.Println("  # Scan what is deployed, from a Terraform state file")
+	fmt.Println("  kiln scan --state terraform.tfstate")
+	fmt.Println()
+	fmt.Println("  # Find changes made outside Terraform")
+	fmt.Println("  kiln drift terraform/ --state terraform.tfstate")
+	fmt.Println()
+	fmt.Println("  # Show which checks back a SOC2 criterion")
+	fmt.Println("  kiln controls show CC6.1")
+	fmt.Println()
+	fmt.Println("  # Fail only on findings added since the baseline")
+	fmt.Println("  kiln baseline create terraform/")
+	fmt.Println("  kiln scan terraform/ --baseline kiln-baseline.json")
+	fmt.Println()
+	fmt.Println("  # Check the project configuration")
+	fmt.Println("  kiln config validate")
+	fmt.Println()
 	fmt.Println("  # Get help for a specific command")
 	fmt.Println("  kiln help scan")
 	fmt.Println()
@@ -173,10 +660,25 @@ func printUsage() {
 func printScanHelp() {
 	fmt.Println("USAGE:")
 	fmt.Println("  kiln scan <path> [options]")
+	fmt.Println("  kiln scan --plan <plan.json> [options]")
+	fmt.Println("  kiln scan --state <terraform.tfstate> [options]")
 	fmt.Println()
 	fmt.Println("DESCRIPTION:")
-	fmt.Println("  Scan Terraform files against SOC2 Trust Service Criteria.")
+	fmt.Println("  Scan Terraform files against SOC2 Trust Service Criteria, or the")
+	fmt.Println("  ISO 27001, HIPAA, PCI DSS and CIS AWS controls selected with --framework.")
+	fmt.Println("  Each check runs once and is reported against every selected framework")
+	fmt.Println("  through the control crosswalk.")
 	fmt.Println("  Supports scanning individual files, multiple files, or entire directories.")
+	fmt.Println("  Reads native (.tf) and JSON (.tf.json) syntax, including CDKTF output.")
+	fmt.Println()
+	fmt.Println("  A comment on or directly above a block accepts a risk in that block:")
+	fmt.Println("    # kiln:ignore CC8.1 reason=\"legacy bucket\" expires=2027-01-01")
+	fmt.Println("  Matching findings are reported as suppressed, with the reason, and")
+	fmt.Println("  left out of the score and exit code until the expiry date.")
+	fmt.Println()
+	fmt.Println("  Settings are read from .kiln.yaml in the scanned directory or a parent,")
+	fmt.Println("  up to the repository root; the options below override them. See")
+	fmt.Println("  kiln help config.")
 	fmt.Println()
 	fmt.Println("ARGUMENTS:")
 	fmt.Println("  <path>       Path to Terraform file(s) or directory to scan")
@@ -184,11 +686,52 @@ func printScanHelp() {
 	fmt.Println()
 	fmt.Println("OPTIONS:")
 	fmt.Println("  -f, --format <format>    Output format (cli, json, html)")
-	fmt.Println("                           Default: cli")
+	fmt.Println("                           Default: cli, or the outputs of .kiln.yaml")
 	fmt.Println()
 	fmt.Println("  -o, --output <file>      Write output to file instead of stdout")
 	fmt.Println("                           Required for html format")
 	fmt.Println()
+	fmt.Println("  --plan <file>            Scan a Terraform plan in JSON format")
+	fmt.Println("                           (output of terraform show -json)")
+	fmt.Println()
+	fmt.Println("  --state <file>           Scan a Terraform state file (format version 4)")
+	fmt.Println("                           to check what is actually deployed")
+	fmt.Println()
+	fmt.Println("  --framework <id>         Framework to scan against (can be repeated or")
+	fmt.Println("                           comma-separated; default: soc2)")
+	fmt.Println("                           soc2, iso27001, hipaa, pci, cis-aws")
+	fmt.Println()
+	fmt.Println("  --pivot <id>             Report findings by the controls of one framework")
+	fmt.Println()
+	fmt.Println("  --policy <dir>           Load extra Rego policies (can be repeated)")
+	fmt.Println("                           A file named like a built-in policy replaces it;")
+	fmt.Println("                           a crosswalk.yaml overrides the controls or")
+	fmt.Println("                           severity of the checks it lists")
+	fmt.Println("                           Alias: --policy-dir")
+	fmt.Println()
+	fmt.Println("  --entrypoint <rule>      Rego rule to evaluate, e.g. data.acme.evaluate")
+	fmt.Println("                           (can be repeated; default: the evaluate rule of")
+	fmt.Println("                           every policy package)")
+	fmt.Println()
+	fmt.Println("  --var-file <file>        Load variable values from a .tfvars file")
+	fmt.Println("                           terraform.tfvars and *.auto.tfvars are loaded automatically")
+	fmt.Println()
+	fmt.Println("  --var <name=value>       Set a variable value (can be repeated)")
+	fmt.Println()
+	fmt.Println("  --exceptions <file>      Exceptions file of approved risk acceptances")
+	fmt.Println("                           Default: kiln-exceptions.yaml in the scanned")
+	fmt.Println("                           directory or a parent, up to the repository root")
+	fmt.Println()
+	fmt.Println("  --baseline <file>        Report findings recorded by kiln baseline create")
+	fmt.Println("                           separately; only new findings fail the scan")
+	fmt.Println()
+	fmt.Println("  --config <file>          Project configuration to use instead of .kiln.yaml")
+	fmt.Println()
+	fmt.Println("  --fail-on <severity>     Fail only on violations of this severity or above")
+	fmt.Println("                           (critical, high, medium, low; default: any)")
+	fmt.Println()
+	fmt.Println("  --min-score <n>          Also fail when the score is below n (0-100)")
+	fmt.Println()
 	fmt.Println("  -q, --quiet              Only output errors (for CI/CD)")
 	fmt.Println()
 	fmt.Println("  -h, --help               Show this help message")
@@ -209,18 +752,112 @@ func printScanHelp() {
 	fmt.Println("  # Generate HTML report")
 	fmt.Println("  kiln scan . --format html --output report.html")
 	fmt.Println()
+	fmt.Println("  # Resolve variables from an environment's tfvars")
+	fmt.Println("  kiln scan terraform/ --var-file prod.tfvars --var environment=production")
+	fmt.Println()
+	fmt.Println("  # Scan the stacks synthesized by CDK for Terraform")
+	fmt.Println("  cdktf synth && kiln scan cdktf.out/stacks/")
+	fmt.Println()
+	fmt.Println("  # Scan fully resolved values from a plan")
+	fmt.Println("  terraform show -json plan.out > plan.json")
+	fmt.Println("  kiln scan --plan plan.json")
+	fmt.Println()
+	fmt.Println("  # Scan deployed resources from state")
+	fmt.Println("  terraform state pull > terraform.tfstate")
+	fmt.Println("  kiln scan --state terraform.tfstate")
+	fmt.Println()
+	fmt.Println("  # Scan against several frameworks, each with its own score")
+	fmt.Println("  kiln scan . --framework soc2,hipaa")
+	fmt.Println()
+	fmt.Println("  # Show the same findings by PCI DSS requirement")
+	fmt.Println("  kiln scan . --framework soc2,pci --pivot pci")
+	fmt.Println()
+	fmt.Println("  # Add organization-specific policies to the built-in ones")
+	fmt.Println("  kiln scan . --policy ./compliance/policies")
+	fmt.Println()
 	fmt.Println("  # Quiet mode for CI (only exit code matters)")
 	fmt.Println("  kiln scan . --quiet")
 	fmt.Println()
+	fmt.Println("  # Block on critical findings only while burning down the rest")
+	fmt.Println("  kiln scan . --fail-on critical --min-score 60")
+	fmt.Println()
+	fmt.Println("EXIT CODES:")
+	fmt.Println("  0    No threshold breached")
+	fmt.Println("  1    Violations at the --fail-on severity, or score below --min-score")
+	fmt.Println("  2    Invalid arguments or configuration")
+	fmt.Println("  3    Files or policies could not be parsed or evaluated, and no")
+	fmt.Println("       threshold was breached")
+	fmt.Println()
+	fmt.Println("SOC2 CONTROLS:")
+	if catalog, err := scanner.LoadCatalog(scanner.Options{}); err == nil {
+		controls, _ := catalog.Framework(scanner.DefaultFramework)
+		for _, control := range controls {
+			fmt.Printf("  %-8s %s\n", control.ID, control.Title)
+		}
+	}
+	fmt.Println("  Other frameworks' controls: kiln controls list")
+	fmt.Println()
+	fmt.Println("FRAMEWORKS:")
+	for _, f := range scanner.Frameworks() {
+		fmt.Printf("  %-10s %s\n", f.ID, f.Name)
+	}
+}
+
+func printDriftHelp() {
+	fmt.Println("USAGE:")
+	fmt.Println("  kiln drift <path> --state <terraform.tfstate> [options]")
+	fmt.Println()
+	fmt.Println("DESCRIPTION:")
+	fmt.Println("  Compare Terraform configuration with what a state file records as")
+	fmt.Println("  deployed, and report security-relevant changes made outside Terraform")
+	fmt.Println("  under change management controls (SOC2 CC8.1, ISO 27001 A.8.32, PCI DSS")
+	fmt.Println("  6.5.1):")
+	fmt.Println("    - security settings that differ from the code (e.g. encryption disabled)")
+	fmt.Println("    - security group rules added out-of-band")
+	fmt.Println("    - resources in state with no configuration")
+	fmt.Println()
+	fmt.Println("ARGUMENTS:")
+	fmt.Println("  <path>       Terraform file or directory holding the configuration")
+	fmt.Println()
+	fmt.Println("OPTIONS:")
+	fmt.Println("  --state <file>           Terraform state file to compare against (required)")
+	fmt.Println()
+	fmt.Println("  --framework <id>         Framework to report against (can be repeated or")
+	fmt.Println("                           comma-separated; default: soc2)")
+	fmt.Println()
+	fmt.Println("  --pivot <id>             Report findings by the controls of one framework")
+	fmt.Println()
+	fmt.Println("  --policy <dir>           Policy directory whose crosswalk.yaml remaps the")
+	fmt.Println("                           controls or severity of the drift checks")
+	fmt.Println()
+	fmt.Println("  -f, --format <format>    Output format (cli, json, html)")
+	fmt.Println("                           Default: cli")
+	fmt.Println()
+	fmt.Println("  -o, --output <file>      Write output to file instead of stdout")
+	fmt.Println()
+	fmt.Println("  --var-file <file>        Load variable values from a .tfvars file")
+	fmt.Println()
+	fmt.Println("  --var <name=value>       Set a variable value (can be repeated)")
+	fmt.Println()
+	fmt.Println("  --exceptions, --baseline, --config, --fail-on and --min-score work as")
+	fmt.Println("  for kiln scan; .kiln.yaml is read the same way.")
+	fmt.Println()
+	fmt.Println("  -q, --quiet              Only output errors (for CI/CD)")
+	fmt.Println()
+	fmt.Println("  -h, --help               Show this help message")
+	fmt.Println()
+	fmt.Println("EXAMPLES:")
+	fmt.Println("  # Compare a directory with state pulled from the backend")
+	fmt.Println("  terraform state pull > terraform.tfstate")
+	fmt.Println("  kiln drift terraform/ --state terraform.tfstate")
+	fmt.Println()
+	fmt.Println("  # Evidence for the auditor")
+	fmt.Println("  kiln drift terraform/ --state terraform.tfstate --format html --output drift.html")
+	fmt.Println()
 	fmt.Println("EXIT CODES:")
-	fmt.Println("  0    All compliance checks passed")
-	fmt.Println("  1    Violations found or scan error")
-	fmt.Println()
-	fmt.Println("SUPPORTED CONTROLS:")
-	fmt.Println("  CC6.1    Logical Access Controls")
-	fmt.Println("  CC6.6    Encryption at Rest")
-	fmt.Println("  CC6.7    Data in Transit Encryption")
-	fmt.Println("  CC7.1    System Availability")
-	fmt.Println("  CC7.2    System Monitoring & Logging")
-	fmt.Println("  CC8.1    Change Management")
+	fmt.Println("  0    No security-relevant drift")
+	fmt.Println("  1    Drift found at the --fail-on severity")
+	fmt.Println("  2    Invalid arguments")
+	fmt.Println("  3    Configuration or state could not be parsed or evaluated, and no")
+	fmt.Println("       drift was found at the --fail-on severity")
 }
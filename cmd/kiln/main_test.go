@@ -0,0 +1,100 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runMainEnv makes the test binary run main with the arguments after "--",
+// so that tests can check the exit codes main passes to os.Exit
+const runMainEnv = "KILN_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		for i, arg := range os.Args {
+			if arg == "--" {
+				os.Args = append([]string{"kiln"}, os.Args[i+1:]...)
+				break
+			}
+		}
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runKiln runs kiln with args and returns its exit code and output
+func runKiln(t *testing.T, args ...string) (int, []byte) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^$", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, output
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), output
+	}
+	t.Fatalf("kiln %v: %v", args, err)
+	return -1, nil
+}
+
+func TestExitCodes(t *testing.T) {
+	// One file that parses, one that doesn't, and every check disabled so
+	// the parsed file passes
+	partial := t.TempDir()
+	for name, content := range map[string]string{
+		"main.tf":    "resource \"aws_s3_bucket\" \"logs\" {\n  bucket = \"acme-logs\"\n}\n",
+		"broken.tf":  "resource \"aws_s3_bucket\" \"broken\" {\n",
+		".kiln.yaml": "checks:\n  disabled: [\"KILN-*\"]\n",
+	} {
+		if err := os.WriteFile(filepath.Join(partial, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A file with violations next to one that doesn't parse
+	failing := t.TempDir()
+	example, err := os.ReadFile(filepath.Join("..", "..", "testdata", "example.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"main.tf":   string(example),
+		"broken.tf": "resource \"aws_s3_bucket\" \"broken\" {\n",
+	} {
+		if err := os.WriteFile(filepath.Join(failing, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"passing scan", []string{"scan", "../../testdata/multifile", "-q"}, exitClean},
+		{"violations", []string{"scan", "../../testdata/example.tf", "-q"}, exitThreshold},
+		{"violations and unscanned files", []string{"scan", failing, "-q"}, exitThreshold},
+		{"unknown flag", []string{"scan", "../../testdata/multifile", "--nope"}, exitUsage},
+		{"undeclared variable", []string{"scan", "../../testdata/variables", "--var", "nope=1", "-q"}, exitUsage},
+		{"unknown config option", []string{"config", "validate", "--strict"}, exitUsage},
+		{"unscanned files", []string{"scan", partial, "-q"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, output := runKiln(t, tt.args...); got != tt.want {
+				t.Errorf("exit code = %d, want %d; output:\n%s", got, tt.want, output)
+			}
+		})
+	}
+}
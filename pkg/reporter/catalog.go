@@ -0,0 +1,130 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+// WriteCatalogJSON writes the controls of a catalog to w as indented JSON
+func WriteCatalogJSON(w io.Writer, catalog *scanner.Catalog) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("generate JSON: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCatalogCLI writes one line per control to w, grouped by framework
+func WriteCatalogCLI(w io.Writer, catalog *scanner.Catalog) error {
+	c := &cliWriter{w: w}
+
+	framework := ""
+	for _, control := range catalog.Controls {
+		if control.Framework != framework {
+			if framework != "" {
+				c.println()
+			}
+			framework = control.Framework
+			c.printf("%s\n", frameworkName(framework))
+		}
+
+		checks := fmt.Sprintf("%d checks", len(control.Checks))
+		if len(control.Checks) == 1 {
+			checks = "1 check"
+		}
+		c.printf("  %-22s %s (%s)\n", control.ID, control.Title, checks)
+	}
+
+	return c.err
+}
+
+// WriteControlsCLI writes the full entry of each control to w: what it
+// requires and the checks and resource types providing evidence for it
+func WriteControlsCLI(w io.Writer, controls []scanner.Control) error {
+	c := &cliWriter{w: w}
+
+	for i, control := range controls {
+		if i > 0 {
+			c.printDivider()
+		}
+		c.println()
+		c.printf("%s  %s\n", control.ID, control.Title)
+		c.printf("Framework: %s\n", frameworkName(control.Framework))
+
+		if control.Description != "" {
+			c.println()
+			for _, line := range wrap(control.Description, 76) {
+				c.printf("  %s\n", line)
+			}
+		}
+
+		if len(control.PointsOfFocus) > 0 {
+			c.println()
+			c.println("Points of focus:")
+			for _, point := range control.PointsOfFocus {
+				c.printf("  • %s\n", point)
+			}
+		}
+
+		c.println()
+		if len(control.Checks) == 0 {
+			c.println("Checks: none — this control needs evidence from outside Terraform")
+		} else {
+			c.println("Checks:")
+			for _, check := range control.Checks {
+				c.printf("  %-18s %-9s %s\n", check.ID, check.Severity, check.Title)
+			}
+		}
+
+		if len(control.ResourceTypes) > 0 {
+			c.println()
+			c.println("Resource types:")
+			for _, t := range control.ResourceTypes {
+				c.printf("  %s\n", t)
+			}
+		}
+		c.println()
+	}
+
+	return c.err
+}
+
+// frameworkName is the display name of a framework ID
+func frameworkName(id string) string {
+	if f, err := scanner.LookupFramework(id); err == nil {
+		return f.Name
+	}
+	return id
+}
+
+// wrap breaks text into lines of at most width characters at spaces
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
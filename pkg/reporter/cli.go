@@ -5,75 +5,145 @@ package reporter
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/usekiln/kiln/pkg/scanner"
 )
 
-// PrintCLI prints scan results to terminal with beautiful formatting
-func PrintCLI(result *scanner.Result) {
-	fmt.Println() // Spacing
+// WriteCLI writes scan results to w, formatted for a terminal
+func WriteCLI(w io.Writer, result *scanner.Result) error {
+	c := &cliWriter{
+		w:              w,
+		frameworks:     assessedFrameworks(result),
+		multiFramework: len(result.Frameworks) > 1,
+	}
+
+	c.println() // Spacing
 
 	// Header
-	printHeader()
+	c.printHeader(result)
 
 	// Audit Readiness Score with visual bar
-	printAuditReadinessScore(result.Score)
-	fmt.Println()
+	c.printAuditReadinessScore(result.Score)
+	if len(result.Frameworks) > 1 {
+		c.printFrameworkScores(result.Frameworks)
+	}
+	c.println()
 
 	// Summary counts
-	printSummary(result)
-	printDivider()
-	fmt.Println()
+	c.printSummary(result)
+	c.printDivider()
+	c.println()
+
+	// Files that could not be scanned
+	if len(result.Errors) > 0 {
+		c.printErrors(result.Errors)
+		c.printDivider()
+		c.println()
+	}
 
 	// Critical violations
 	if len(result.Violations) > 0 {
-		printViolations(result.Violations)
-		printDivider()
-		fmt.Println()
+		c.printViolations(result.Violations)
+		c.printDivider()
+		c.println()
 	}
 
 	// Warnings
 	if len(result.Warnings) > 0 {
-		printWarnings(result.Warnings)
-		printDivider()
-		fmt.Println()
+		c.printWarnings(result.Warnings)
+		c.printDivider()
+		c.println()
+	}
+
+	// Exceptions that need attention
+	if exceptionWarnings(result) > 0 {
+		c.printExceptionWarnings(result.Exceptions)
+		c.printDivider()
+		c.println()
+	}
+
+	// Accepted risks
+	if len(result.Suppressed) > 0 {
+		c.printSuppressed(result.Suppressed)
+		c.printDivider()
+		c.println()
+	}
+
+	// Findings deferred by the baseline (condensed)
+	if len(result.Baselined) > 0 || result.BaselineResolved > 0 {
+		c.printBaselined(result)
+		c.printDivider()
+		c.println()
 	}
 
 	// Passed checks (condensed)
 	if len(result.Passed) > 0 {
-		printPassed(result.Passed)
-		fmt.Println()
+		c.printPassed(result.Passed)
+		c.println()
 	}
 
 	// Next steps
 	if len(result.Violations) > 0 || len(result.Warnings) > 0 {
-		printNextSteps(result)
+		c.printNextSteps(result)
 	} else {
-		printAllGood(result)
+		c.printAllGood(result)
 	}
 
 	// Footer disclaimer
-	printFooter()
+	c.printFooter(result)
+
+	c.println() // Spacing
+
+	return c.err
+}
 
-	fmt.Println() // Spacing
+// cliWriter writes to w, keeping the first error so that the many small
+// writes of a report don't each need checking
+type cliWriter struct {
+	w   io.Writer
+	err error
+
+	// frameworks were assessed; multiFramework qualifies control IDs with
+	// their framework when more than one framework or package was scored
+	frameworks     []scanner.Framework
+	multiFramework bool
+}
+
+func (c *cliWriter) print(a ...interface{}) {
+	if c.err == nil {
+		_, c.err = fmt.Fprint(c.w, a...)
+	}
+}
+
+func (c *cliWriter) println(a ...interface{}) {
+	if c.err == nil {
+		_, c.err = fmt.Fprintln(c.w, a...)
+	}
 }
 
-func printHeader() {
+func (c *cliWriter) printf(format string, a ...interface{}) {
+	if c.err == nil {
+		_, c.err = fmt.Fprintf(c.w, format, a...)
+	}
+}
+
+func (c *cliWriter) printHeader(result *scanner.Result) {
 	bold := colorBold
 	cyan := colorCyan
 
-	fmt.Print(bold)
-	fmt.Print("🔥 ")
-	fmt.Print(cyan)
-	fmt.Print("Kiln ")
-	fmt.Print(colorReset)
-	fmt.Print("v0.1.0 - SOC2 Trust Service Criteria Scanner")
-	fmt.Println()
-	fmt.Println()
+	c.print(bold)
+	c.print("🔥 ")
+	c.print(cyan)
+	c.print("Kiln ")
+	c.print(colorReset)
+	c.printf("v0.1.0 - %s Scanner", frameworkTitle(result))
+	c.println()
+	c.println()
 }
 
-func printAuditReadinessScore(score int) {
+func (c *cliWriter) printAuditReadinessScore(score int) {
 	bold := colorBold
 
 	// Determine color based on score
@@ -94,182 +164,440 @@ func printAuditReadinessScore(score int) {
 	}
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barLength-filled)
 
-	fmt.Print(bold)
-	fmt.Print("📊 Audit Readiness: ")
-	fmt.Print(scoreColor)
-	fmt.Printf("%d/100 ", score)
-	fmt.Print(colorReset)
-	fmt.Println(bar)
+	c.print(bold)
+	c.print("📊 Audit Readiness: ")
+	c.print(scoreColor)
+	c.printf("%d/100 ", score)
+	c.print(colorReset)
+	c.println(bar)
 }
 
-func printSummary(result *scanner.Result) {
-	fmt.Println()
+// printFrameworkScores lists the score of each framework scanned
+func (c *cliWriter) printFrameworkScores(scores []scanner.FrameworkScore) {
+	for _, s := range scores {
+		c.print(colorGray)
+		c.printf("   %-40s %3d/100  ❌ %d  ⚠️  %d  ✅ %d\n", s.Name, s.Score, s.Violations, s.Warnings, s.Passed)
+		c.print(colorReset)
+	}
+}
+
+// control labels a finding's controls, e.g. "SOC2 CC6.6 · HIPAA
+// 164.312(a)(2)(iv)" when several frameworks were scanned
+func (c *cliWriter) control(f scanner.Finding) string {
+	return controlLabel(f, c.frameworks, c.multiFramework)
+}
+
+func (c *cliWriter) printSummary(result *scanner.Result) {
+	c.println()
 
 	if len(result.Passed) > 0 {
-		fmt.Print(colorGreen)
-		fmt.Printf("✅ %d controls implemented\n", len(result.Passed))
-		fmt.Print(colorReset)
+		c.print(colorGreen)
+		c.printf("✅ %d controls implemented\n", len(result.Passed))
+		c.print(colorReset)
 	}
 
 	if len(result.Warnings) > 0 {
-		fmt.Print(colorYellow)
-		fmt.Printf("⚠️  %d warnings found\n", len(result.Warnings))
-		fmt.Print(colorReset)
+		c.print(colorYellow)
+		c.printf("⚠️  %d warnings found\n", len(result.Warnings))
+		c.print(colorReset)
 	}
 
 	if len(result.Violations) > 0 {
-		fmt.Print(colorRed)
-		fmt.Printf("❌ %d critical gaps found\n", len(result.Violations))
-		fmt.Print(colorReset)
+		c.print(colorRed)
+		c.printf("❌ %d critical gaps found\n", len(result.Violations))
+		c.print(colorReset)
+	}
+
+	if len(result.Suppressed) > 0 {
+		c.print(colorGray)
+		c.printf("🔕 %d suppressed (accepted risks)\n", len(result.Suppressed))
+		c.print(colorReset)
 	}
 
-	fmt.Println()
+	if len(result.Baselined) > 0 {
+		c.print(colorGray)
+		c.printf("📌 %d in baseline (existing findings, not failing)\n", len(result.Baselined))
+		c.print(colorReset)
+	}
+
+	if len(result.Errors) > 0 {
+		c.print(colorRed)
+		c.printf("🚫 %d parse errors (affected files or blocks were skipped)\n", len(result.Errors))
+		c.print(colorReset)
+	}
+
+	c.println()
 }
 
-func printViolations(violations []scanner.Finding) {
+func (c *cliWriter) printErrors(errors []scanner.ScanError) {
+	bold := colorBold + colorRed
+	gray := colorGray
+
+	c.print(bold)
+	c.println("Parse Errors (skipped):")
+	c.print(colorReset)
+	c.println()
+
+	for _, e := range errors {
+		c.print(colorRed)
+		if loc := e.Location(); loc != "" {
+			c.printf("🚫 %s\n", loc)
+		} else {
+			c.println("🚫 (unknown location)")
+		}
+		c.print(colorReset)
+
+		c.print(gray)
+		c.printf("   └─ %s\n", e.Message)
+		c.print(colorReset)
+
+		c.println()
+	}
+}
+
+func (c *cliWriter) printViolations(violations []scanner.Finding) {
 	bold := colorBold + colorRed
 	gray := colorGray
 	white := colorWhite
 	yellow := colorYellow
 
-	fmt.Print(bold)
-	fmt.Println("Critical Control Gaps:")
-	fmt.Print(colorReset)
-	fmt.Println()
+	c.print(bold)
+	c.println("Critical Control Gaps:")
+	c.print(colorReset)
+	c.println()
 
 	for _, v := range violations {
 		severity := getSeverityIcon(v.Severity)
 
 		// Control and message
-		fmt.Print(colorRed)
-		fmt.Printf("%s %s - %s\n", severity, v.Control, v.Message)
-		fmt.Print(colorReset)
+		c.print(colorRed)
+		c.printf("%s %s - %s\n", severity, c.control(v), v.Message)
+		c.print(colorReset)
 
 		// Resource
 		if v.Resource != "" {
-			fmt.Print(white)
-			fmt.Printf("   └─ Resource: %s\n", v.Resource)
-			fmt.Print(colorReset)
+			c.print(white)
+			c.printf("   └─ Resource: %s\n", v.Resource)
+			c.print(colorReset)
+		}
+
+		// Source location
+		if loc := v.Location(); loc != "" {
+			c.print(white)
+			c.printf("   └─ Location: %s\n", loc)
+			c.print(colorReset)
 		}
 
+		// Check and fingerprint, for tracking the finding across scans
+		c.printFindingID(v)
+		c.printExpiredSuppression(v)
+		c.printExpiredException(v)
+
 		// Remediation
 		if v.Remediation != "" {
-			fmt.Print(gray)
-			fmt.Printf("   └─ Fix: %s\n", v.Remediation)
-			fmt.Print(colorReset)
+			c.print(gray)
+			c.printf("   └─ Fix: %s\n", v.Remediation)
+			c.print(colorReset)
+		}
+
+		// Documentation
+		for _, ref := range v.References {
+			c.print(gray)
+			c.printf("   └─ Docs: %s\n", ref)
+			c.print(colorReset)
 		}
 
 		// Impact note for critical items
-		fmt.Print(yellow)
-		fmt.Println("   └─ Impact: Required for SOC2 audit")
-		fmt.Print(colorReset)
+		c.print(yellow)
+		c.printf("   └─ Impact: %s\n", findingImpact(v, c.frameworks))
+		c.print(colorReset)
 
-		fmt.Println()
+		c.println()
 	}
 }
 
-func printWarnings(warnings []scanner.Finding) {
+func (c *cliWriter) printWarnings(warnings []scanner.Finding) {
 	bold := colorBold + colorYellow
 	gray := colorGray
 	white := colorWhite
 
-	fmt.Print(bold)
-	fmt.Println("Warnings (Auditor Recommendations):")
-	fmt.Print(colorReset)
-	fmt.Println()
+	c.print(bold)
+	c.println("Warnings (Auditor Recommendations):")
+	c.print(colorReset)
+	c.println()
 
 	for _, w := range warnings {
 		severity := getSeverityIcon(w.Severity)
 
-		fmt.Print(colorYellow)
-		fmt.Printf("%s %s - %s\n", severity, w.Control, w.Message)
-		fmt.Print(colorReset)
+		c.print(colorYellow)
+		c.printf("%s %s - %s\n", severity, c.control(w), w.Message)
+		c.print(colorReset)
 
 		if w.Resource != "" {
-			fmt.Print(white)
-			fmt.Printf("   └─ Resource: %s\n", w.Resource)
-			fmt.Print(colorReset)
+			c.print(white)
+			c.printf("   └─ Resource: %s\n", w.Resource)
+			c.print(colorReset)
 		}
 
+		if loc := w.Location(); loc != "" {
+			c.print(white)
+			c.printf("   └─ Location: %s\n", loc)
+			c.print(colorReset)
+		}
+
+		c.printFindingID(w)
+		c.printExpiredSuppression(w)
+		c.printExpiredException(w)
+
 		if w.Remediation != "" {
-			fmt.Print(gray)
-			fmt.Printf("   └─ Fix: %s\n", w.Remediation)
-			fmt.Print(colorReset)
+			c.print(gray)
+			c.printf("   └─ Fix: %s\n", w.Remediation)
+			c.print(colorReset)
 		}
 
-		fmt.Println()
+		for _, ref := range w.References {
+			c.print(gray)
+			c.printf("   └─ Docs: %s\n", ref)
+			c.print(colorReset)
+		}
+
+		c.println()
+	}
+}
+
+// printFindingID prints the check ID and fingerprint of a finding
+func (c *cliWriter) printFindingID(f scanner.Finding) {
+	if f.Fingerprint == "" {
+		return
+	}
+	c.print(colorGray)
+	if f.CheckID != "" {
+		c.printf("   └─ ID: %s · %s\n", f.CheckID, f.Fingerprint)
+	} else {
+		c.printf("   └─ ID: %s\n", f.Fingerprint)
+	}
+	c.print(colorReset)
+}
+
+// printExpiredSuppression notes that a finding was suppressed by a
+// kiln:ignore comment that has since expired
+func (c *cliWriter) printExpiredSuppression(f scanner.Finding) {
+	if f.Suppression == nil || !f.Suppression.Expired {
+		return
+	}
+	c.print(colorYellow)
+	c.printf("   └─ Suppression expired %s (%s:%d): %s\n",
+		f.Suppression.Expires, f.Suppression.File, f.Suppression.Line, f.Suppression.Reason)
+	c.print(colorReset)
+}
+
+// printExpiredException notes that a finding was covered by an exception
+// that has since expired
+func (c *cliWriter) printExpiredException(f scanner.Finding) {
+	if f.Exception == nil || !f.Exception.Expired {
+		return
+	}
+	c.print(colorYellow)
+	c.printf("   └─ Exception expired %s (owner %s, %s)\n",
+		f.Exception.Expires, f.Exception.Owner, f.Exception.Ticket)
+	c.print(colorReset)
+}
+
+// printExceptionWarnings lists the exceptions that are expiring, expired or
+// no longer match anything
+func (c *cliWriter) printExceptionWarnings(exceptions []scanner.ExceptionStatus) {
+	c.print(colorBold + colorYellow)
+	c.println("Exceptions Needing Review:")
+	c.print(colorReset)
+	c.println()
+
+	for _, e := range exceptions {
+		if warning := e.Warning(); warning != "" {
+			c.print(colorYellow)
+			c.printf("⏰ %s\n", warning)
+			c.print(colorReset)
+		}
+	}
+	c.println()
+}
+
+// exceptionWarnings counts the exceptions of result that need review
+func exceptionWarnings(result *scanner.Result) int {
+	n := 0
+	for _, e := range result.Exceptions {
+		if e.Warning() != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// printSuppressed lists the findings accepted by kiln:ignore comments or
+// exceptions, with the justification auditors will ask for
+func (c *cliWriter) printSuppressed(suppressed []scanner.Finding) {
+	bold := colorBold + colorGray
+	gray := colorGray
+	white := colorWhite
+
+	c.print(bold)
+	c.println("Suppressed (Accepted Risks):")
+	c.print(colorReset)
+	c.println()
+
+	for _, f := range suppressed {
+		c.print(white)
+		c.printf("🔕 %s - %s\n", c.control(f), f.Message)
+		c.print(colorReset)
+
+		if f.Resource != "" {
+			c.print(gray)
+			c.printf("   └─ Resource: %s\n", f.Resource)
+			c.print(colorReset)
+		}
+
+		if loc := f.Location(); loc != "" {
+			c.print(gray)
+			c.printf("   └─ Location: %s\n", loc)
+			c.print(colorReset)
+		}
+
+		c.printFindingID(f)
+
+		if s := f.Suppression; s != nil {
+			c.print(gray)
+			c.printf("   └─ Reason: %s\n", s.Reason)
+			if s.Expires != "" {
+				c.printf("   └─ Expires: %s\n", s.Expires)
+			} else {
+				c.println("   └─ Expires: never")
+			}
+			c.printf("   └─ Suppressed at: %s:%d\n", s.File, s.Line)
+			c.print(colorReset)
+		}
+
+		if e := f.Exception; e != nil {
+			c.print(gray)
+			if e.Reason != "" {
+				c.printf("   └─ Reason: %s\n", e.Reason)
+			}
+			c.printf("   └─ Exception: %s (owner %s, approved by %s)\n", e.Ticket, e.Owner, e.Approver)
+			c.printf("   └─ Expires: %s\n", e.Expires)
+			c.print(colorReset)
+		}
+
+		c.println()
 	}
 }
 
-func printPassed(passed []scanner.Finding) {
-	fmt.Print(colorGreen)
-	fmt.Printf("✅ %d Controls Implemented\n", len(passed))
-	fmt.Print(colorReset)
+// printBaselined summarizes the findings recorded in the baseline and the
+// baseline findings that have since been fixed
+func (c *cliWriter) printBaselined(result *scanner.Result) {
+	c.print(colorBold)
+	c.printf("📌 %d Findings In Baseline\n", len(result.Baselined))
+	c.print(colorReset)
+
+	maxShow := 5
+	for i, f := range result.Baselined {
+		if i >= maxShow {
+			c.print(colorGray)
+			c.printf("   ... and %d more (see --format json)\n", len(result.Baselined)-maxShow)
+			c.print(colorReset)
+			break
+		}
+		c.print(colorGray)
+		c.printf("   • %s: %s\n", c.control(f), f.Message)
+		c.print(colorReset)
+	}
+
+	if result.BaselineResolved > 0 {
+		c.print(colorGreen)
+		c.printf("   🎉 %d baseline findings resolved; run 'kiln baseline create' to lock in progress\n", result.BaselineResolved)
+		c.print(colorReset)
+	}
+	c.println()
+}
+
+func (c *cliWriter) printPassed(passed []scanner.Finding) {
+	c.print(colorGreen)
+	c.printf("✅ %d Controls Implemented\n", len(passed))
+	c.print(colorReset)
 
 	// Show first few controls
 	maxShow := 3
 	for i, p := range passed {
 		if i >= maxShow {
 			remaining := len(passed) - maxShow
-			fmt.Print(colorGray)
-			fmt.Printf("   ... and %d more\n", remaining)
-			fmt.Print(colorReset)
+			c.print(colorGray)
+			c.printf("   ... and %d more\n", remaining)
+			c.print(colorReset)
 			break
 		}
-		fmt.Print(colorGray)
-		fmt.Printf("   • %s: %s\n", p.Control, p.Message)
-		fmt.Print(colorReset)
+		c.print(colorGray)
+		c.printf("   • %s: %s\n", c.control(p), p.Message)
+		c.print(colorReset)
 	}
 }
 
-func printNextSteps(result *scanner.Result) {
+func (c *cliWriter) printNextSteps(result *scanner.Result) {
 	bold := colorBold
 
-	fmt.Print(bold)
-	fmt.Println("💡 Next Steps:")
-	fmt.Print(colorReset)
+	c.print(bold)
+	c.println("💡 Next Steps:")
+	c.print(colorReset)
 
 	if len(result.Violations) > 0 {
-		fmt.Println("   1. Fix critical gaps (required for SOC2 audit)")
+		c.printf("   1. Fix critical gaps (required for %s)\n", joinAssessments(assessedFrameworks(result)))
 	}
 	if len(result.Warnings) > 0 {
-		fmt.Println("   2. Review warnings (auditor recommendations)")
+		c.println("   2. Review warnings (auditor recommendations)")
 	}
 
-	fmt.Print(colorCyan)
-	fmt.Println("   3. Re-scan with: kiln scan <file>")
-	fmt.Print(colorReset)
+	c.print(colorCyan)
+	c.println("   3. Re-scan with: kiln scan <file>")
+	c.print(colorReset)
 }
 
-func printAllGood(result *scanner.Result) {
+func (c *cliWriter) printAllGood(result *scanner.Result) {
 	bold := colorBold
 	green := colorGreen
 
-	fmt.Print(bold)
-	fmt.Print(green)
-	fmt.Println("🎉 Excellent! No critical gaps found.")
-	fmt.Print(colorReset)
-	fmt.Println()
-	fmt.Println("Your infrastructure code aligns well with SOC2 Trust Service Criteria.")
-	fmt.Println()
-	fmt.Print(colorGray)
-	fmt.Println("Remember: Kiln scans infrastructure code only. A full SOC2 audit will also")
-	fmt.Println("review organizational policies, procedures, and control operation over time.")
-	fmt.Print(colorReset)
+	c.print(bold)
+	c.print(green)
+	if len(result.Baselined) > 0 {
+		c.println("🎉 No new gaps since the baseline.")
+		c.print(colorReset)
+		c.println()
+		c.printf("Fix the %d findings in the baseline to align with %s.\n", len(result.Baselined), frameworkTitle(result))
+	} else {
+		c.println("🎉 Excellent! No critical gaps found.")
+		c.print(colorReset)
+		c.println()
+		c.printf("Your infrastructure code aligns well with %s.\n", frameworkTitle(result))
+	}
+	c.println()
+	c.print(colorGray)
+	c.println("Remember: Kiln scans infrastructure code only. A full audit will also review")
+	c.println("organizational policies, procedures, and control operation over time.")
+	c.print(colorReset)
 }
 
-func printFooter() {
-	fmt.Println()
-	fmt.Print(colorGray)
-	fmt.Println("Note: Kiln identifies potential control gaps. It does not certify SOC2 compliance.")
-	fmt.Println("      A formal audit by a licensed CPA firm is required for SOC2 compliance.")
-	fmt.Print(colorReset)
+func (c *cliWriter) printFooter(result *scanner.Result) {
+	frameworks := assessedFrameworks(result)
+
+	c.println()
+	c.print(colorGray)
+	c.printf("Note: Kiln identifies potential control gaps. It does not certify %s compliance.\n",
+		joinShortNames(frameworks, " or "))
+	if includesSOC2(frameworks) {
+		c.println("      A formal audit by a licensed CPA firm is required for SOC2 compliance.")
+	}
+	c.print(colorReset)
 }
 
-func printDivider() {
-	fmt.Print(colorGray)
-	fmt.Println(strings.Repeat("━", 50))
-	fmt.Print(colorReset)
+func (c *cliWriter) printDivider() {
+	c.print(colorGray)
+	c.println(strings.Repeat("━", 50))
+	c.print(colorReset)
 }
 
 // ANSI color codes
@@ -0,0 +1,68 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+// PrintCLI prints scan results to stdout, formatted for a terminal
+//
+// Deprecated: Use WriteCLI, which returns errors instead of exiting.
+func PrintCLI(result *scanner.Result) {
+	exitOnError(WriteCLI(os.Stdout, result))
+}
+
+// PrintJSON writes scan results as JSON to outputFile, or to stdout when
+// outputFile is empty
+//
+// Deprecated: Use WriteJSON, which returns errors instead of exiting.
+func PrintJSON(result *scanner.Result, outputFile string) {
+	printReport(result, outputFile, WriteJSON)
+}
+
+// PrintHTML writes scan results as an HTML report to outputFile
+//
+// Deprecated: Use WriteHTML, which returns errors instead of exiting.
+func PrintHTML(result *scanner.Result, outputFile string) {
+	if outputFile == "" {
+		fmt.Println("❌ Error: --output flag is required for HTML format")
+		fmt.Println("   Example: kiln scan main.tf --format html --output report.html")
+		os.Exit(1)
+	}
+	printReport(result, outputFile, WriteHTML)
+}
+
+// printReport writes a report to outputFile, or to stdout when it is empty,
+// exiting on errors as the Print functions always have
+func printReport(result *scanner.Result, outputFile string, write func(io.Writer, *scanner.Result) error) {
+	if outputFile == "" {
+		exitOnError(write(os.Stdout, result))
+		return
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		exitOnError(fmt.Errorf("create file: %w", err))
+	}
+	if err := write(file, result); err != nil {
+		file.Close()
+		exitOnError(err)
+	}
+	exitOnError(file.Close())
+
+	fmt.Printf("✅ Report saved to: %s\n", outputFile)
+}
+
+// exitOnError prints err and exits when it is set
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Printf("❌ Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+}
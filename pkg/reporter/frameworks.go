@@ -0,0 +1,106 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package reporter
+
+import (
+	"strings"
+
+	"github.com/usekiln/kiln/pkg/scanner"
+)
+
+// assessedFrameworks returns the frameworks a result was scored against.
+// Results without framework scores, such as drift, are SOC2.
+func assessedFrameworks(result *scanner.Result) []scanner.Framework {
+	var frameworks []scanner.Framework
+	for _, score := range result.Frameworks {
+		if f, err := scanner.LookupFramework(score.Framework); err == nil {
+			frameworks = append(frameworks, f)
+		}
+	}
+	if len(frameworks) == 0 {
+		f, _ := scanner.LookupFramework(scanner.DefaultFramework)
+		frameworks = append(frameworks, f)
+	}
+	return frameworks
+}
+
+// frameworkTitle names what a report covers, e.g. "SOC2 Trust Service
+// Criteria" or "SOC2 + HIPAA"
+func frameworkTitle(result *scanner.Result) string {
+	frameworks := assessedFrameworks(result)
+	if len(frameworks) == 1 {
+		return frameworks[0].Name
+	}
+	return joinShortNames(frameworks, " + ")
+}
+
+// joinShortNames joins the short names of frameworks, e.g. "SOC2, HIPAA"
+func joinShortNames(frameworks []scanner.Framework, sep string) string {
+	names := make([]string, len(frameworks))
+	for i, f := range frameworks {
+		names[i] = f.ShortName
+	}
+	return strings.Join(names, sep)
+}
+
+// joinAssessments joins what the frameworks' gaps put at risk, e.g.
+// "SOC2 audit, HIPAA risk analysis"
+func joinAssessments(frameworks []scanner.Framework) string {
+	assessments := make([]string, len(frameworks))
+	for i, f := range frameworks {
+		assessments[i] = f.Assessment
+	}
+	return strings.Join(assessments, ", ")
+}
+
+// includesSOC2 reports whether SOC2 is among the frameworks
+func includesSOC2(frameworks []scanner.Framework) bool {
+	for _, f := range frameworks {
+		if f.ID == "soc2" {
+			return true
+		}
+	}
+	return false
+}
+
+// controlLabel labels a finding's controls. With qualify, as when several
+// frameworks were scored, each control is prefixed with its framework, e.g.
+// "SOC2 CC6.6 · PCI DSS 3.5.1", since IDs like 2.1.1 are ambiguous.
+func controlLabel(f scanner.Finding, frameworks []scanner.Framework, qualify bool) string {
+	if !qualify {
+		return f.Control
+	}
+	if len(f.Controls) == 0 {
+		if f.Package != "" {
+			return f.Package + " " + f.Control
+		}
+		return f.Control
+	}
+
+	var labels []string
+	for _, framework := range frameworks {
+		if controls := f.ControlsIn(framework.ID); len(controls) > 0 {
+			labels = append(labels, framework.ShortName+" "+strings.Join(controls, ", "))
+		}
+	}
+	return strings.Join(labels, " · ")
+}
+
+// findingImpact describes what an unresolved violation puts at risk in the
+// frameworks assessed
+func findingImpact(f scanner.Finding, frameworks []scanner.Framework) string {
+	var affected []scanner.Framework
+	for _, framework := range frameworks {
+		if len(f.ControlsIn(framework.ID)) > 0 {
+			affected = append(affected, framework)
+		}
+	}
+	if len(affected) > 0 {
+		return "Required for " + joinAssessments(affected)
+	}
+	if f.Package != "" && len(f.Controls) == 0 {
+		return "Required by " + f.Package + " policies"
+	}
+	return "Required for SOC2 audit"
+}
@@ -6,7 +6,7 @@ package reporter
 import (
 	"fmt"
 	"html/template"
-	"os"
+	"io"
 
 	"github.com/usekiln/kiln/pkg/scanner"
 )
@@ -92,6 +92,9 @@ const htmlTemplate = `<!DOCTYPE html>
         .card-passed { background: #d4edda; color: #155724; }
         .card-warnings { background: #fff3cd; color: #856404; }
         .card-violations { background: #f8d7da; color: #721c24; }
+        .card-errors { background: #e2e3e5; color: #383d41; }
+        .card-framework { background: #ede7f6; color: #4527a0; }
+        .framework-counts { font-size: 0.85em; margin-top: 5px; }
         .section {
             padding: 30px;
             border-top: 1px solid #e9ecef;
@@ -112,6 +115,7 @@ const htmlTemplate = `<!DOCTYPE html>
         .finding-medium { border-left-color: #ffc107; background: #fff3cd; }
         .finding-low { border-left-color: #17a2b8; background: #d1ecf1; }
         .finding-passed { border-left-color: #28a745; background: #d4edda; }
+        .finding-error { border-left-color: #343a40; background: #e2e3e5; }
         .finding-header {
             display: flex;
             align-items: center;
@@ -145,6 +149,11 @@ const htmlTemplate = `<!DOCTYPE html>
             display: inline-block;
             margin-top: 5px;
         }
+        .finding-location {
+            font-family: monospace;
+            font-size: 0.85em;
+            margin-top: 5px;
+        }
         .finding-remediation {
             margin-top: 10px;
             padding: 10px;
@@ -152,6 +161,34 @@ const htmlTemplate = `<!DOCTYPE html>
             border-radius: 4px;
             font-size: 0.9em;
         }
+        .finding-id {
+            font-family: monospace;
+            font-size: 0.8em;
+            color: #6c757d;
+            margin-top: 5px;
+        }
+        .finding-suppressed { border-left-color: #adb5bd; background: #f1f3f5; }
+        .finding-suppression {
+            margin-top: 8px;
+            font-size: 0.9em;
+            color: #495057;
+        }
+        .exceptions { width: 100%; border-collapse: collapse; font-size: 0.9em; }
+        .exceptions th, .exceptions td {
+            text-align: left;
+            padding: 8px;
+            border-bottom: 1px solid #e9ecef;
+            vertical-align: top;
+            word-break: break-word;
+        }
+        .exception-expiring td { background: #fff3cd; }
+        .exception-expired td { background: #f8d7da; }
+        .exception-stale td { background: #e2e3e5; }
+        .finding-reference {
+            margin-top: 6px;
+            font-size: 0.85em;
+            word-break: break-all;
+        }
         .footer {
             background: #f8f9fa;
             padding: 20px;
@@ -172,7 +209,7 @@ const htmlTemplate = `<!DOCTYPE html>
         <div class="header">
             <div class="emoji">🔥</div>
             <h1>Kiln Compliance Report</h1>
-            <p>SOC2 Trust Service Criteria Analysis</p>
+            <p>{{.Title}} Analysis</p>
         </div>
 
         <div class="score-section">
@@ -183,6 +220,18 @@ const htmlTemplate = `<!DOCTYPE html>
             <p class="timestamp">Scanned: {{.ScannedAt}}</p>
         </div>
 
+        {{if gt (len .Frameworks) 1}}
+        <div class="summary">
+            {{range .Frameworks}}
+            <div class="summary-card card-framework">
+                <div class="number">{{.Score}}%</div>
+                <div class="label">{{.Name}}</div>
+                <div class="framework-counts">{{.Violations}} gaps · {{.Warnings}} warnings · {{.Passed}} passing</div>
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
         <div class="summary">
             <div class="summary-card card-passed">
                 <div class="number">{{.PassedCount}}</div>
@@ -196,8 +245,34 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="number">{{.ViolationCount}}</div>
                 <div class="label">Critical Gaps</div>
             </div>
+            {{if .Errors}}
+            <div class="summary-card card-errors">
+                <div class="number">{{.ErrorCount}}</div>
+                <div class="label">Parse Errors</div>
+            </div>
+            {{end}}
         </div>
 
+        {{if .Errors}}
+        <div class="section">
+            <h2>🚫 Parse Errors</h2>
+            <p style="margin-bottom: 15px;">These files or blocks could not be parsed and were skipped. Findings below cover the rest of the configuration.</p>
+            {{range .Errors}}
+            <div class="finding finding-error">
+                <div class="finding-header">
+                    <span class="finding-icon">🚫</span>
+                    <span class="finding-message">{{.Message}}</span>
+                </div>
+                {{if .File}}
+                <div class="finding-details">
+                    <div class="finding-location">{{.Location}}</div>
+                </div>
+                {{end}}
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
         {{if .Violations}}
         <div class="section">
             <h2>❌ Critical Control Gaps</h2>
@@ -205,20 +280,27 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="finding finding-{{.Severity}}">
                 <div class="finding-header">
                     <span class="finding-icon">❌</span>
-                    <span class="finding-control">{{.Control}}</span>
+                    <span class="finding-control">{{control .}}</span>
                     <span class="finding-message">{{.Message}}</span>
                 </div>
                 {{if .Resource}}
                 <div class="finding-details">
                     <strong>Resource:</strong>
                     <div class="finding-resource">{{.Resource}}</div>
+                    {{if .File}}<div class="finding-location">{{.Location}}{{if .EndLine}}–{{.EndLine}}{{end}}</div>{{end}}
                 </div>
                 {{end}}
+                {{if .Fingerprint}}<div class="finding-id">{{with .CheckID}}{{.}} · {{end}}{{.Fingerprint}}</div>{{end}}
+                {{with .Suppression}}{{if .Expired}}<div class="finding-suppression">⏰ Suppression expired {{.Expires}} ({{.File}}:{{.Line}}): {{.Reason}}</div>{{end}}{{end}}
+                {{with .Exception}}{{if .Expired}}<div class="finding-suppression">⏰ Exception expired {{.Expires}} (owner {{.Owner}}, <a href="{{.Ticket}}">{{.Ticket}}</a>)</div>{{end}}{{end}}
                 {{if .Remediation}}
                 <div class="finding-remediation">
                     <strong>💡 How to fix:</strong> {{.Remediation}}
                 </div>
                 {{end}}
+                {{range .References}}
+                <div class="finding-reference">📖 <a href="{{.}}">{{.}}</a></div>
+                {{end}}
             </div>
             {{end}}
         </div>
@@ -231,20 +313,85 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="finding finding-{{.Severity}}">
                 <div class="finding-header">
                     <span class="finding-icon">⚠️</span>
-                    <span class="finding-control">{{.Control}}</span>
+                    <span class="finding-control">{{control .}}</span>
                     <span class="finding-message">{{.Message}}</span>
                 </div>
                 {{if .Resource}}
                 <div class="finding-details">
                     <strong>Resource:</strong>
                     <div class="finding-resource">{{.Resource}}</div>
+                    {{if .File}}<div class="finding-location">{{.Location}}{{if .EndLine}}–{{.EndLine}}{{end}}</div>{{end}}
                 </div>
                 {{end}}
+                {{if .Fingerprint}}<div class="finding-id">{{with .CheckID}}{{.}} · {{end}}{{.Fingerprint}}</div>{{end}}
+                {{with .Suppression}}{{if .Expired}}<div class="finding-suppression">⏰ Suppression expired {{.Expires}} ({{.File}}:{{.Line}}): {{.Reason}}</div>{{end}}{{end}}
+                {{with .Exception}}{{if .Expired}}<div class="finding-suppression">⏰ Exception expired {{.Expires}} (owner {{.Owner}}, <a href="{{.Ticket}}">{{.Ticket}}</a>)</div>{{end}}{{end}}
                 {{if .Remediation}}
                 <div class="finding-remediation">
                     <strong>💡 Recommendation:</strong> {{.Remediation}}
                 </div>
                 {{end}}
+                {{range .References}}
+                <div class="finding-reference">📖 <a href="{{.}}">{{.}}</a></div>
+                {{end}}
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .Suppressed}}
+        <div class="section">
+            <h2>🔕 Suppressed (Accepted Risks)</h2>
+            <p style="margin-bottom: 15px;">These findings are accepted by kiln:ignore comments in the code or by the exceptions file, and left out of the score until they expire.</p>
+            {{range .Suppressed}}
+            <div class="finding finding-suppressed">
+                <div class="finding-header">
+                    <span class="finding-icon">🔕</span>
+                    <span class="finding-control">{{control .}}</span>
+                    <span class="finding-message">{{.Message}}</span>
+                </div>
+                {{if .Resource}}
+                <div class="finding-details">
+                    <strong>Resource:</strong>
+                    <div class="finding-resource">{{.Resource}}</div>
+                    {{if .File}}<div class="finding-location">{{.Location}}{{if .EndLine}}–{{.EndLine}}{{end}}</div>{{end}}
+                </div>
+                {{end}}
+                {{if .Fingerprint}}<div class="finding-id">{{with .CheckID}}{{.}} · {{end}}{{.Fingerprint}}</div>{{end}}
+                {{with .Suppression}}
+                <div class="finding-suppression">
+                    <strong>Reason:</strong> {{.Reason}} · <strong>Expires:</strong> {{if .Expires}}{{.Expires}}{{else}}never{{end}} · {{.File}}:{{.Line}}
+                </div>
+                {{end}}
+                {{with .Exception}}
+                <div class="finding-suppression">
+                    {{if .Reason}}<strong>Reason:</strong> {{.Reason}} · {{end}}<strong>Owner:</strong> {{.Owner}} · <strong>Approver:</strong> {{.Approver}} · <strong>Expires:</strong> {{.Expires}} · <a href="{{.Ticket}}">{{.Ticket}}</a>
+                </div>
+                {{end}}
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .Baselined}}
+        <div class="section">
+            <h2>📌 In Baseline ({{len .Baselined}})</h2>
+            <p style="margin-bottom: 15px;">These findings existed when the baseline was created. They still count toward the score but don't fail the scan.{{if .BaselineResolved}} {{.BaselineResolved}} baseline findings have been resolved since.{{end}}</p>
+            {{range .Baselined}}
+            <div class="finding finding-suppressed">
+                <div class="finding-header">
+                    <span class="finding-icon">📌</span>
+                    <span class="finding-control">{{control .}}</span>
+                    <span class="finding-message">{{.Message}}</span>
+                </div>
+                {{if .Resource}}
+                <div class="finding-details">
+                    <strong>Resource:</strong>
+                    <div class="finding-resource">{{.Resource}}</div>
+                    {{if .File}}<div class="finding-location">{{.Location}}{{if .EndLine}}–{{.EndLine}}{{end}}</div>{{end}}
+                </div>
+                {{end}}
+                {{if .Fingerprint}}<div class="finding-id">{{with .CheckID}}{{.}} · {{end}}{{.Fingerprint}}</div>{{end}}
             </div>
             {{end}}
         </div>
@@ -257,73 +404,95 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="finding finding-passed">
                 <div class="finding-header">
                     <span class="finding-icon">✅</span>
-                    <span class="finding-control">{{.Control}}</span>
+                    <span class="finding-control">{{control .}}</span>
                     <span class="finding-message">{{.Message}}</span>
                 </div>
                 {{if .Resource}}
                 <div class="finding-details">
                     <strong>Resource:</strong>
                     <div class="finding-resource">{{.Resource}}</div>
+                    {{if .File}}<div class="finding-location">{{.Location}}{{if .EndLine}}–{{.EndLine}}{{end}}</div>{{end}}
                 </div>
                 {{end}}
+                {{if .Fingerprint}}<div class="finding-id">{{with .CheckID}}{{.}} · {{end}}{{.Fingerprint}}</div>{{end}}
             </div>
             {{end}}
         </div>
         {{end}}
 
+        {{if .Exceptions}}
+        <div class="section">
+            <h2>📋 Appendix: Exceptions</h2>
+            <p style="margin-bottom: 15px;">Risk acceptances from the exceptions file, with their approvals and the findings they covered in this scan.</p>
+            <table class="exceptions">
+                <tr><th>Exception</th><th>Owner</th><th>Approver</th><th>Ticket</th><th>Expires</th><th>Status</th><th>Findings</th></tr>
+                {{range .Exceptions}}
+                <tr class="exception-{{.Status}}">
+                    <td>{{if .Fingerprint}}<code>{{.Fingerprint}}</code>{{else}}<code>{{.Check}}</code>{{with .Resource}} on <code>{{.}}</code>{{end}}{{end}}{{with .Reason}}<br>{{.}}{{end}}</td>
+                    <td>{{.Owner}}</td>
+                    <td>{{.Approver}}</td>
+                    <td><a href="{{.Ticket}}">{{.Ticket}}</a></td>
+                    <td>{{.Expires}}</td>
+                    <td>{{.Status}}</td>
+                    <td>{{len .Findings}}</td>
+                </tr>
+                {{end}}
+            </table>
+        </div>
+        {{end}}
+
         <div class="footer">
-            <p><strong>Important:</strong> Kiln identifies potential control gaps. It does not certify SOC2 compliance.</p>
-            <p>A formal audit by a licensed CPA firm is required for SOC2 compliance.</p>
+            <p><strong>Important:</strong> Kiln identifies potential control gaps. It does not certify {{.Certifies}} compliance.</p>
+            {{if .IncludesSOC2}}<p>A formal audit by a licensed CPA firm is required for SOC2 compliance.</p>{{end}}
             <p style="margin-top: 15px;">Generated by Kiln v0.1.0 • <a href="https://github.com/usekiln/kiln">github.com/usekiln/kiln</a></p>
         </div>
     </div>
 </body>
 </html>`
 
-// PrintHTML outputs scan results as an HTML report
-func PrintHTML(result *scanner.Result, outputFile string) {
-	if outputFile == "" {
-		fmt.Println("❌ Error: --output flag is required for HTML format")
-		fmt.Println("   Example: kiln scan main.tf --format html --output report.html")
-		os.Exit(1)
-	}
+// WriteHTML writes scan results to w as a standalone HTML report
+func WriteHTML(w io.Writer, result *scanner.Result) error {
+	frameworks := assessedFrameworks(result)
 
 	// Prepare template data
 	data := map[string]interface{}{
-		"Score":          result.Score,
-		"ScoreClass":     getScoreClass(result.Score),
-		"ScannedAt":      result.ScannedAt,
-		"PassedCount":    len(result.Passed),
-		"WarningCount":   len(result.Warnings),
-		"ViolationCount": len(result.Violations),
-		"Violations":     result.Violations,
-		"Warnings":       result.Warnings,
-		"Passed":         result.Passed,
+		"Title":            frameworkTitle(result),
+		"Certifies":        joinShortNames(frameworks, " or "),
+		"IncludesSOC2":     includesSOC2(frameworks),
+		"Frameworks":       result.Frameworks,
+		"Score":            result.Score,
+		"ScoreClass":       getScoreClass(result.Score),
+		"ScannedAt":        result.ScannedAt,
+		"PassedCount":      len(result.Passed),
+		"WarningCount":     len(result.Warnings),
+		"ViolationCount":   len(result.Violations),
+		"ErrorCount":       len(result.Errors),
+		"Violations":       result.Violations,
+		"Warnings":         result.Warnings,
+		"Passed":           result.Passed,
+		"Suppressed":       result.Suppressed,
+		"Exceptions":       result.Exceptions,
+		"Baselined":        result.Baselined,
+		"BaselineResolved": result.BaselineResolved,
+		"Errors":           result.Errors,
 	}
 
 	// Parse and execute template
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	qualify := len(result.Frameworks) > 1
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"control": func(f scanner.Finding) string {
+			return controlLabel(f, frameworks, qualify)
+		},
+	}).Parse(htmlTemplate)
 	if err != nil {
-		fmt.Printf("❌ Error creating template: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("create template: %w", err)
 	}
 
-	// Create output file
-	file, err := os.Create(outputFile)
-	if err != nil {
-		fmt.Printf("❌ Error creating file: %v\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	// Write report
-	err = tmpl.Execute(file, data)
-	if err != nil {
-		fmt.Printf("❌ Error writing report: %v\n", err)
-		os.Exit(1)
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("write report: %w", err)
 	}
 
-	fmt.Printf("✅ HTML report saved to: %s\n", outputFile)
+	return nil
 }
 
 func getScoreClass(score int) string {
@@ -6,64 +6,84 @@ package reporter
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/usekiln/kiln/pkg/scanner"
 )
 
 // JSONReport represents the JSON output structure
 type JSONReport struct {
-	Version    string            `json:"version"`
-	Score      int               `json:"score"`
-	ScannedAt  string            `json:"scanned_at"`
-	Summary    Summary           `json:"summary"`
-	Violations []scanner.Finding `json:"violations"`
-	Warnings   []scanner.Finding `json:"warnings"`
-	Passed     []scanner.Finding `json:"passed"`
+	Version    string              `json:"version"`
+	Score      int                 `json:"score"`
+	ScannedAt  string              `json:"scanned_at"`
+	Summary    Summary             `json:"summary"`
+	Violations []scanner.Finding   `json:"violations"`
+	Warnings   []scanner.Finding   `json:"warnings"`
+	Passed     []scanner.Finding   `json:"passed"`
+	Suppressed []scanner.Finding   `json:"suppressed"`
+	Baselined  []scanner.Finding   `json:"baselined,omitempty"`
+	Errors     []scanner.ScanError `json:"errors"`
+
+	Frameworks []scanner.FrameworkScore `json:"frameworks,omitempty"`
+
+	// Exceptions is the appendix of exceptions applied, with their status
+	Exceptions []scanner.ExceptionStatus `json:"exceptions,omitempty"`
 }
 
 // Summary provides count metrics
 type Summary struct {
-	TotalChecks    int `json:"total_checks"`
-	PassedChecks   int `json:"passed_checks"`
-	WarningCount   int `json:"warning_count"`
-	ViolationCount int `json:"violation_count"`
-	CriticalCount  int `json:"critical_count"`
-	HighCount      int `json:"high_count"`
-	MediumCount    int `json:"medium_count"`
-	LowCount       int `json:"low_count"`
+	TotalChecks     int `json:"total_checks"`
+	PassedChecks    int `json:"passed_checks"`
+	WarningCount    int `json:"warning_count"`
+	ViolationCount  int `json:"violation_count"`
+	CriticalCount   int `json:"critical_count"`
+	HighCount       int `json:"high_count"`
+	MediumCount     int `json:"medium_count"`
+	LowCount        int `json:"low_count"`
+	SuppressedCount int `json:"suppressed_count"`
+	BaselinedCount  int `json:"baselined_count"`
+	ResolvedCount   int `json:"baseline_resolved_count"`
+	ErrorCount      int `json:"error_count"`
 }
 
-// PrintJSON outputs scan results as JSON
-func PrintJSON(result *scanner.Result, outputFile string) {
-	report := buildJSONReport(result)
+// WriteJSON writes scan results to w as indented JSON
+func WriteJSON(w io.Writer, result *scanner.Result) error {
+	data, err := json.MarshalIndent(buildJSONReport(result), "", "  ")
+	if err != nil {
+		return fmt.Errorf("generate JSON: %w", err)
+	}
 
-	// Marshal to JSON
-	data, err := json.MarshalIndent(report, "", "  ")
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+
+	return nil
+}
+
+// WriteBaselineJSON writes a baseline to w as indented JSON
+func WriteBaselineJSON(w io.Writer, baseline *scanner.Baseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
 	if err != nil {
-		fmt.Printf("Error generating JSON: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("generate JSON: %w", err)
 	}
 
-	// Write to file or stdout
-	if outputFile != "" {
-		err = os.WriteFile(outputFile, data, 0644)
-		if err != nil {
-			fmt.Printf("Error writing to file: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("✅ Report saved to: %s\n", outputFile)
-	} else {
-		fmt.Println(string(data))
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
 	}
+
+	return nil
 }
 
 func buildJSONReport(result *scanner.Result) JSONReport {
 	summary := Summary{
-		TotalChecks:    len(result.Violations) + len(result.Warnings) + len(result.Passed),
-		PassedChecks:   len(result.Passed),
-		WarningCount:   len(result.Warnings),
-		ViolationCount: len(result.Violations),
+		TotalChecks:     len(result.Violations) + len(result.Warnings) + len(result.Passed) + len(result.Baselined),
+		PassedChecks:    len(result.Passed),
+		WarningCount:    len(result.Warnings),
+		ViolationCount:  len(result.Violations),
+		SuppressedCount: len(result.Suppressed),
+		BaselinedCount:  len(result.Baselined),
+		ResolvedCount:   result.BaselineResolved,
+		ErrorCount:      len(result.Errors),
 	}
 
 	// Count by severity
@@ -89,6 +109,16 @@ func buildJSONReport(result *scanner.Result) JSONReport {
 		}
 	}
 
+	// Always emit a list, even for results built without one
+	scanErrors := result.Errors
+	if scanErrors == nil {
+		scanErrors = []scanner.ScanError{}
+	}
+	suppressed := result.Suppressed
+	if suppressed == nil {
+		suppressed = []scanner.Finding{}
+	}
+
 	return JSONReport{
 		Version:    "0.1.0",
 		Score:      result.Score,
@@ -97,5 +127,10 @@ func buildJSONReport(result *scanner.Result) JSONReport {
 		Violations: result.Violations,
 		Warnings:   result.Warnings,
 		Passed:     result.Passed,
+		Suppressed: suppressed,
+		Baselined:  result.Baselined,
+		Errors:     scanErrors,
+		Frameworks: result.Frameworks,
+		Exceptions: result.Exceptions,
 	}
 }
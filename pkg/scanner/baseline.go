@@ -0,0 +1,125 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// BaselineFile is the default name of a baseline written by kiln baseline
+// create
+const BaselineFile = "kiln-baseline.json"
+
+// baselineVersion is the format version of baseline files
+const baselineVersion = 1
+
+// Baseline is a snapshot of the violations and warnings of a scan, by
+// fingerprint. Scanning with it reports those findings as Baselined, so
+// that only new findings fail a build while existing ones are burned down.
+type Baseline struct {
+	Version   int               `json:"version"`
+	CreatedAt string            `json:"created_at"`
+	Findings  []BaselineFinding `json:"findings"`
+}
+
+// BaselineFinding is a finding recorded in a baseline. Only the fingerprint
+// is matched; the rest helps reviewers of the baseline file.
+type BaselineFinding struct {
+	Fingerprint string `json:"fingerprint"`
+	CheckID     string `json:"check_id,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	File        string `json:"file,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// NewBaseline snapshots the violations and warnings of result, sorted by
+// fingerprint so that regenerating a baseline gives a readable diff
+func NewBaseline(result *Result) *Baseline {
+	b := &Baseline{
+		Version:   baselineVersion,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Findings:  []BaselineFinding{},
+	}
+	for _, findings := range [][]Finding{result.Violations, result.Warnings} {
+		for _, f := range findings {
+			b.Findings = append(b.Findings, BaselineFinding{
+				Fingerprint: f.Fingerprint,
+				CheckID:     f.CheckID,
+				Severity:    f.Severity,
+				Resource:    f.Resource,
+				File:        f.File,
+				Message:     f.Message,
+			})
+		}
+	}
+	sort.Slice(b.Findings, func(i, j int) bool {
+		return b.Findings[i].Fingerprint < b.Findings[j].Fingerprint
+	})
+	return b
+}
+
+// ParseBaseline parses a baseline file
+func ParseBaseline(content []byte) (*Baseline, error) {
+	var b Baseline
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, err
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d (expected %d)", b.Version, baselineVersion)
+	}
+	for i, f := range b.Findings {
+		if f.Fingerprint == "" {
+			return nil, fmt.Errorf("finding %d has no fingerprint", i+1)
+		}
+	}
+	return &b, nil
+}
+
+// LoadBaseline reads and parses the baseline file at path
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ParseBaseline(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// ApplyBaseline moves the violations and warnings recorded in b to
+// Baselined. The score still counts them: a baseline defers findings, it
+// doesn't accept them. Baseline entries no longer found are counted in
+// BaselineResolved, so the baseline can be regenerated to lock in progress.
+func (r *Result) ApplyBaseline(b *Baseline) {
+	known := make(map[string]bool, len(b.Findings))
+	for _, f := range b.Findings {
+		known[f.Fingerprint] = true
+	}
+
+	if r.Baselined == nil {
+		r.Baselined = []Finding{}
+	}
+	found := make(map[string]bool)
+	for _, findings := range []*[]Finding{&r.Violations, &r.Warnings} {
+		current := (*findings)[:0]
+		for _, f := range *findings {
+			if known[f.Fingerprint] {
+				found[f.Fingerprint] = true
+				r.Baselined = append(r.Baselined, f)
+				continue
+			}
+			current = append(current, f)
+		}
+		*findings = current
+	}
+
+	r.BaselineResolved = len(known) - len(found)
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputeFingerprint(t *testing.T) {
+	base := Finding{
+		CheckID:   "KILN-S3-001",
+		Resource:  "aws_s3_bucket.logs",
+		File:      "main.tf",
+		Message:   "S3 bucket has no encryption",
+		StartLine: 10,
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Finding)
+		same   bool
+	}{
+		{"message reworded", func(f *Finding) { f.Message = "Bucket is unencrypted" }, true},
+		{"line moved", func(f *Finding) { f.StartLine = 42 }, true},
+		{"severity overridden", func(f *Finding) { f.Severity = "low" }, true},
+		{"other check", func(f *Finding) { f.CheckID = "KILN-S3-002" }, false},
+		{"other resource", func(f *Finding) { f.Resource = "aws_s3_bucket.exports" }, false},
+		{"other file", func(f *Finding) { f.File = "other.tf" }, false},
+	}
+
+	want := base.ComputeFingerprint()
+	if len(want) != 16 {
+		t.Fatalf("fingerprint %q is not 16 characters", want)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := base
+			tt.change(&f)
+			if got := f.ComputeFingerprint(); (got == want) != tt.same {
+				t.Errorf("fingerprint %s, base %s: same = %v, want %v", got, want, got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintIsRelativeToRepository(t *testing.T) {
+	// Two checkouts of the same repository agree on fingerprints
+	var fingerprints []string
+	for i := 0; i < 2; i++ {
+		repo := writeFiles(t, map[string]string{".git/HEAD": "ref: refs/heads/main\n"})
+		f := Finding{CheckID: "KILN-S3-001", Resource: "aws_s3_bucket.logs", File: filepath.Join(repo, "infra", "main.tf")}
+		fingerprints = append(fingerprints, f.ComputeFingerprint())
+	}
+	if fingerprints[0] != fingerprints[1] {
+		t.Errorf("checkouts disagree: %s and %s", fingerprints[0], fingerprints[1])
+	}
+}
+
+func TestAttachFingerprintsSeparatesRepeatedFindings(t *testing.T) {
+	result := &Result{
+		Violations: []Finding{
+			{CheckID: "KILN-DRIFT-001", Resource: "aws_s3_bucket.logs", Message: "acl drifted"},
+			{CheckID: "KILN-DRIFT-001", Resource: "aws_s3_bucket.logs", Message: "versioning drifted"},
+		},
+	}
+	attachFingerprints(result)
+
+	a, b := result.Violations[0].Fingerprint, result.Violations[1].Fingerprint
+	if a == "" || a == b {
+		t.Errorf("fingerprints %q and %q should differ", a, b)
+	}
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	scan := func() *Result {
+		return &Result{
+			Violations: []Finding{
+				{CheckID: "KILN-S3-001", Severity: "high", Resource: "aws_s3_bucket.a", File: "main.tf"},
+				{CheckID: "KILN-S3-002", Severity: "medium", Resource: "aws_s3_bucket.a", File: "main.tf"},
+			},
+			Warnings: []Finding{
+				{CheckID: "KILN-S3-003", Severity: "low", Resource: "aws_s3_bucket.a", File: "main.tf"},
+			},
+		}
+	}
+
+	first := scan()
+	attachFingerprints(first)
+	content, err := json.Marshal(NewBaseline(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), BaselineFile)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline.Findings) != 3 {
+		t.Fatalf("baseline has %d findings, want 3", len(baseline.Findings))
+	}
+
+	tests := []struct {
+		name           string
+		change         func(*Result)
+		wantViolations int
+		wantBaselined  int
+		wantResolved   int
+	}{
+		{
+			name:          "unchanged",
+			change:        func(*Result) {},
+			wantBaselined: 3,
+		},
+		{
+			name: "new finding",
+			change: func(r *Result) {
+				r.Violations = append(r.Violations, Finding{CheckID: "KILN-S3-001", Resource: "aws_s3_bucket.b", File: "main.tf"})
+			},
+			wantViolations: 1,
+			wantBaselined:  3,
+		},
+		{
+			name: "fixed finding",
+			change: func(r *Result) {
+				r.Violations = r.Violations[1:]
+			},
+			wantBaselined: 2,
+			wantResolved:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scan()
+			tt.change(result)
+			attachFingerprints(result)
+			result.ApplyBaseline(baseline)
+
+			if len(result.Violations) != tt.wantViolations {
+				t.Errorf("violations = %d, want %d", len(result.Violations), tt.wantViolations)
+			}
+			if len(result.Baselined) != tt.wantBaselined {
+				t.Errorf("baselined = %d, want %d", len(result.Baselined), tt.wantBaselined)
+			}
+			if result.BaselineResolved != tt.wantResolved {
+				t.Errorf("resolved = %d, want %d", result.BaselineResolved, tt.wantResolved)
+			}
+		})
+	}
+}
+
+func TestParseBaseline(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"valid", `{"version": 1, "findings": [{"fingerprint": "0123456789abcdef"}]}`, ""},
+		{"empty", `{"version": 1, "findings": []}`, ""},
+		{"other version", `{"version": 2, "findings": []}`, "unsupported baseline version 2"},
+		{"no fingerprint", `{"version": 1, "findings": [{"check_id": "KILN-S3-001"}]}`, "finding 1 has no fingerprint"},
+		{"not json", `version: 1`, "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBaseline([]byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
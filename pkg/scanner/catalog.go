@@ -0,0 +1,268 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ControlsFile is the name of a control catalog in a policy directory
+const ControlsFile = "controls.yaml"
+
+// ControlInfo describes what a control requires
+type ControlInfo struct {
+	Title         string   `yaml:"title"`
+	Description   string   `yaml:"description"`
+	PointsOfFocus []string `yaml:"points_of_focus"`
+}
+
+// ControlInfos holds control descriptions by framework ID, then control ID
+type ControlInfos map[string]map[string]ControlInfo
+
+// ParseControls parses a controls.yaml document:
+//
+//	controls:
+//	  soc2:
+//	    CC6.1:
+//	      title: Logical Access Controls
+//	      description: The entity implements logical access security ...
+//	      points_of_focus:
+//	        - Restricts Logical Access
+func ParseControls(content []byte) (ControlInfos, error) {
+	var doc struct {
+		Controls ControlInfos `yaml:"controls"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	for framework := range doc.Controls {
+		if _, err := LookupFramework(framework); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Controls, nil
+}
+
+// Merge adds the entries of other to c, replacing controls with the same
+// framework and ID
+func (c ControlInfos) Merge(other ControlInfos) {
+	for framework, controls := range other {
+		if c[framework] == nil {
+			c[framework] = make(map[string]ControlInfo)
+		}
+		for id, info := range controls {
+			c[framework][id] = info
+		}
+	}
+}
+
+// Catalog lists the controls of every framework with the checks that
+// provide evidence for them
+type Catalog struct {
+	Controls []Control `json:"controls"`
+}
+
+// Control is an entry of the catalog
+type Control struct {
+	Framework     string         `json:"framework"`
+	ID            string         `json:"id"`
+	Title         string         `json:"title,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	PointsOfFocus []string       `json:"points_of_focus,omitempty"`
+	Checks        []CatalogCheck `json:"checks"`
+	ResourceTypes []string       `json:"resource_types"`
+}
+
+// CatalogCheck is a check implementing a control
+type CatalogCheck struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// LoadCatalog builds the catalog of the policies opts selects. Frameworks
+// in opts are ignored; the catalog covers them all.
+func LoadCatalog(opts Options) (*Catalog, error) {
+	policies, err := compilePolicies(opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(policies.crosswalk, policies.controls), nil
+}
+
+// LoadChecks returns the metadata of the checks of the policies opts
+// select, by check ID
+func LoadChecks(opts Options) (Crosswalk, error) {
+	policies, err := compilePolicies(opts)
+	if err != nil {
+		return nil, err
+	}
+	return policies.crosswalk, nil
+}
+
+// compilePolicies loads and compiles the policies opts select
+func compilePolicies(opts Options) (*policySet, error) {
+	policies, err := loadPolicies(opts.policyConfig())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policies.compile(); err != nil {
+		return nil, fmt.Errorf("compile policies: %w", err)
+	}
+	return policies, nil
+}
+
+// NewCatalog builds a catalog from the crosswalk of the checks and control
+// descriptions.
+// Controls described but implemented by no check are listed with no checks.
+func NewCatalog(crosswalk Crosswalk, infos ControlInfos) *Catalog {
+	type key struct{ framework, id string }
+	controls := make(map[key]*Control)
+	control := func(framework, id string) *Control {
+		k := key{framework, id}
+		if controls[k] == nil {
+			info := infos[framework][id]
+			controls[k] = &Control{
+				Framework:     framework,
+				ID:            id,
+				Title:         info.Title,
+				Description:   info.Description,
+				PointsOfFocus: info.PointsOfFocus,
+				Checks:        []CatalogCheck{},
+				ResourceTypes: []string{},
+			}
+		}
+		return controls[k]
+	}
+
+	for framework, described := range infos {
+		for id := range described {
+			control(framework, id)
+		}
+	}
+
+	checkIDs := make([]string, 0, len(crosswalk))
+	for id := range crosswalk {
+		checkIDs = append(checkIDs, id)
+	}
+	sort.Strings(checkIDs)
+
+	for _, checkID := range checkIDs {
+		entry := crosswalk[checkID]
+		for framework, ids := range entry.Controls {
+			for _, id := range ids {
+				c := control(framework, id)
+				c.Checks = append(c.Checks, CatalogCheck{ID: checkID, Title: entry.Title, Severity: entry.Severity})
+				for _, t := range entry.ResourceTypes {
+					if !containsString(c.ResourceTypes, t) {
+						c.ResourceTypes = append(c.ResourceTypes, t)
+					}
+				}
+			}
+		}
+	}
+
+	catalog := &Catalog{Controls: make([]Control, 0, len(controls))}
+	for _, c := range controls {
+		sort.Strings(c.ResourceTypes)
+		catalog.Controls = append(catalog.Controls, *c)
+	}
+	sort.Slice(catalog.Controls, func(i, j int) bool {
+		a, b := catalog.Controls[i], catalog.Controls[j]
+		if a.Framework != b.Framework {
+			return frameworkIndex(a.Framework) < frameworkIndex(b.Framework)
+		}
+		return lessControlID(a.ID, b.ID)
+	})
+	return catalog
+}
+
+// Framework returns the controls of one framework
+func (c *Catalog) Framework(id string) ([]Control, error) {
+	f, err := LookupFramework(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var controls []Control
+	for _, control := range c.Controls {
+		if control.Framework == f.ID {
+			controls = append(controls, control)
+		}
+	}
+	return controls, nil
+}
+
+// Find returns the controls with an ID, ignoring case. IDs such as 3.1 can
+// belong to more than one framework.
+func (c *Catalog) Find(id string) []Control {
+	var controls []Control
+	for _, control := range c.Controls {
+		if strings.EqualFold(control.ID, id) {
+			controls = append(controls, control)
+		}
+	}
+	return controls
+}
+
+// frameworkIndex orders frameworks as they're listed by Frameworks
+func frameworkIndex(id string) int {
+	for i, f := range frameworks {
+		if f.ID == id {
+			return i
+		}
+	}
+	return len(frameworks)
+}
+
+// lessControlID orders control IDs by their numbers, so 3.2 comes before
+// 3.10 and CC6.7 before CC7.1
+func lessControlID(a, b string) bool {
+	pa, pb := splitControlID(a), splitControlID(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] == pb[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return pa[i] < pb[i]
+	}
+	return len(pa) < len(pb)
+}
+
+// splitControlID splits an ID into runs of digits and of other characters,
+// e.g. CC6.1 into CC, 6, ., 1
+func splitControlID(id string) []string {
+	var parts []string
+	start := 0
+	var prev rune
+	for i, r := range id {
+		if i > start && unicode.IsDigit(r) != unicode.IsDigit(prev) {
+			parts = append(parts, id[start:i])
+			start = i
+		}
+		prev = r
+	}
+	return append(parts, id[start:])
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
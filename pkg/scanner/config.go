@@ -0,0 +1,207 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the project configuration file, found by
+// FindConfig
+const ConfigFile = ".kiln.yaml"
+
+// Config is a project configuration file, .kiln.yaml, kept with the code
+// it configures scans of:
+//
+//	frameworks: [soc2, hipaa]
+//	policies: [compliance/policies]
+//	checks:
+//	  disabled: [KILN-S3-006]
+//	severity:
+//	  KILN-S3-003: high
+//	fail_on: high
+//	min_score: 70
+//	exclude: [examples/**]
+//	required_tags: [Environment, Owner, CostCenter]
+//	outputs:
+//	  - format: cli
+//	  - format: html
+//	    file: kiln-report.html
+//	exceptions: kiln-exceptions.yaml
+//
+// Relative paths are relative to the directory of the file.
+type Config struct {
+	Policies     []string          `yaml:"policies,omitempty"`
+	Frameworks   []string          `yaml:"frameworks,omitempty"`
+	Checks       CheckSelection    `yaml:"checks,omitempty"`
+	Severity     map[string]string `yaml:"severity,omitempty"`      // severity overrides by check ID
+	FailOn       string            `yaml:"fail_on,omitempty"`       // lowest violation severity that fails a scan
+	MinScore     *int              `yaml:"min_score,omitempty"`     // lowest passing score
+	Exclude      []string          `yaml:"exclude,omitempty"`       // see Options.Exclude
+	RequiredTags []string          `yaml:"required_tags,omitempty"` // see Options.RequiredTags
+	Outputs      []OutputConfig    `yaml:"outputs,omitempty"`
+	Exceptions   string            `yaml:"exceptions,omitempty"` // exceptions file
+
+	// Path is the file the configuration was loaded from, if any
+	Path string `yaml:"-"`
+}
+
+// CheckSelection enables or disables checks by ID pattern, e.g. KILN-S3-*
+type CheckSelection struct {
+	Enabled  []string `yaml:"enabled,omitempty"`
+	Disabled []string `yaml:"disabled,omitempty"`
+}
+
+// OutputConfig is a report to write: a format and the file to write it
+// to, or stdout when empty
+type OutputConfig struct {
+	Format string `yaml:"format"`
+	File   string `yaml:"file,omitempty"`
+}
+
+// OutputFormats are the report formats kiln scan writes
+var OutputFormats = []string{"cli", "json", "html"}
+
+// ParseConfig parses and validates a configuration file. Unknown keys are
+// errors, so that typos don't silently change what is scanned.
+func ParseConfig(content []byte) (*Config, error) {
+	var c Config
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadConfig reads the configuration file at path, resolving its relative
+// paths against the file's directory
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c.Path = path
+	dir := filepath.Dir(path)
+	for i, p := range c.Policies {
+		c.Policies[i] = resolvePath(dir, p)
+	}
+	if c.Exceptions != "" {
+		c.Exceptions = resolvePath(dir, c.Exceptions)
+	}
+	for i, o := range c.Outputs {
+		if o.File != "" {
+			c.Outputs[i].File = resolvePath(dir, o.File)
+		}
+	}
+	// Patterns with a slash match paths from the configuration's directory
+	for i, pattern := range c.Exclude {
+		if strings.Contains(strings.TrimSuffix(pattern, "/"), "/") && !pathpkg.IsAbs(pattern) {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, err
+			}
+			c.Exclude[i] = pathpkg.Join(filepath.ToSlash(abs), pattern)
+		}
+	}
+
+	return c, nil
+}
+
+// FindConfig looks for .kiln.yaml in the directory of path and its parents,
+// stopping at the root of the repository. It returns "" when there is none.
+func FindConfig(path string) string {
+	return findUp(path, ConfigFile)
+}
+
+// resolvePath makes a path in a configuration file relative to dir
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Validate checks the values of a configuration
+func (c *Config) Validate() error {
+	for _, id := range c.Frameworks {
+		if _, err := LookupFramework(id); err != nil {
+			return fmt.Errorf("frameworks: %w", err)
+		}
+	}
+	for id, severity := range c.Severity {
+		if !containsString(severities, severity) {
+			return fmt.Errorf("severity: check %s: invalid severity %q: expected critical, high, medium or low", id, severity)
+		}
+	}
+	if c.FailOn != "" && !containsString(severities, c.FailOn) {
+		return fmt.Errorf("fail_on: invalid severity %q: expected critical, high, medium or low", c.FailOn)
+	}
+	if c.MinScore != nil && (*c.MinScore < 0 || *c.MinScore > 100) {
+		return fmt.Errorf("min_score: %d is not between 0 and 100", *c.MinScore)
+	}
+	for _, pattern := range c.Exclude {
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := pathpkg.Match(segment, ""); err != nil {
+				return fmt.Errorf("exclude: invalid pattern %q", pattern)
+			}
+		}
+	}
+	for _, tag := range c.RequiredTags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("required_tags: tag names can't be empty")
+		}
+	}
+	for i, o := range c.Outputs {
+		if !containsString(OutputFormats, o.Format) {
+			return fmt.Errorf("outputs: %d: unknown format %q: expected cli, json or html", i+1, o.Format)
+		}
+		if o.Format == "html" && o.File == "" {
+			return fmt.Errorf("outputs: %d: html output needs a file", i+1)
+		}
+	}
+	return nil
+}
+
+// UnmatchedChecks lists the check ID patterns of the configuration that
+// match none of checks. They are most likely typos.
+func (c *Config) UnmatchedChecks(checks Crosswalk) []string {
+	var unmatched []string
+	patterns := append(append([]string{}, c.Checks.Enabled...), c.Checks.Disabled...)
+	for id := range c.Severity {
+		patterns = append(patterns, id)
+	}
+	for _, pattern := range patterns {
+		found := false
+		for id := range checks {
+			if globMatch(pattern, id) {
+				found = true
+				break
+			}
+		}
+		if !found && !containsString(unmatched, pattern) {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
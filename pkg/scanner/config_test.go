@@ -0,0 +1,163 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name: "every option",
+			content: `frameworks: [soc2, hipaa]
+policies: [compliance/policies]
+checks:
+  disabled: [KILN-S3-006]
+severity:
+  KILN-S3-003: high
+fail_on: high
+min_score: 70
+exclude: [examples/**]
+required_tags: [Environment, Owner]
+outputs:
+  - format: cli
+  - format: html
+    file: kiln-report.html
+exceptions: kiln-exceptions.yaml
+`,
+		},
+		{
+			name:    "unknown key",
+			content: "fail-on: high\n",
+			wantErr: "field fail-on not found",
+		},
+		{
+			name:    "unknown framework",
+			content: "frameworks: [soc3]\n",
+			wantErr: "frameworks:",
+		},
+		{
+			name:    "invalid severity override",
+			content: "severity:\n  KILN-S3-003: urgent\n",
+			wantErr: `check KILN-S3-003: invalid severity "urgent"`,
+		},
+		{
+			name:    "invalid fail_on",
+			content: "fail_on: severe\n",
+			wantErr: `fail_on: invalid severity "severe"`,
+		},
+		{
+			name:    "min_score out of range",
+			content: "min_score: 101\n",
+			wantErr: "min_score: 101 is not between 0 and 100",
+		},
+		{
+			name:    "invalid exclude pattern",
+			content: "exclude: ['examples/[']\n",
+			wantErr: `exclude: invalid pattern "examples/["`,
+		},
+		{
+			name:    "empty required tag",
+			content: "required_tags: ['']\n",
+			wantErr: "required_tags: tag names can't be empty",
+		},
+		{
+			name:    "unknown output format",
+			content: "outputs:\n  - format: sarif\n",
+			wantErr: `outputs: 1: unknown format "sarif"`,
+		},
+		{
+			name:    "html output without a file",
+			content: "outputs:\n  - format: html\n",
+			wantErr: "outputs: 1: html output needs a file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigResolvesPaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{ConfigFile: `policies: [policies]
+exceptions: kiln-exceptions.yaml
+outputs:
+  - format: json
+    file: reports/kiln.json
+exclude: ['*.bak', 'examples/**']
+`})
+	c, err := LoadConfig(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"policies", c.Policies[0], filepath.Join(dir, "policies")},
+		{"exceptions", c.Exceptions, filepath.Join(dir, "kiln-exceptions.yaml")},
+		{"output file", c.Outputs[0].File, filepath.Join(dir, "reports", "kiln.json")},
+		{"exclude by name", c.Exclude[0], "*.bak"},
+		{"exclude by path", c.Exclude[1], filepath.ToSlash(abs) + "/examples/**"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.bak.tf", "/repo/main.bak.tf", true},
+		{"*.bak.tf", "/repo/modules/a/old.bak.tf", true},
+		{"*.bak.tf", "/repo/main.tf", false},
+		{"examples/**", "/repo/examples/basic/main.tf", true},
+		{"examples/**", "/repo/modules/examples/main.tf", false},
+		{"**/examples/**", "/repo/modules/examples/main.tf", true},
+		{"modules/*/test.tf", "/repo/modules/a/test.tf", true},
+		{"modules/*/test.tf", "/repo/modules/a/b/test.tf", false},
+		{"modules/**/test.tf", "/repo/modules/a/b/test.tf", true},
+		{"/repo/examples/**", "/repo/examples/main.tf", true},
+	}
+
+	for _, tt := range tests {
+		if got := excluded([]string{tt.pattern}, root, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("excluded(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
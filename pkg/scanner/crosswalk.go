@@ -0,0 +1,203 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CrosswalkFile is the name of a control crosswalk in a policy directory
+const CrosswalkFile = "crosswalk.yaml"
+
+// Crosswalk maps check IDs, e.g. KILN-S3-001, to what is known about each
+// check: the controls it provides evidence for, its severity and
+// remediation. It is read from the METADATA annotations of the policies,
+// with crosswalk.yaml files merged over it, so checks are written once and
+// reported against any framework.
+type Crosswalk map[string]CrosswalkEntry
+
+// CrosswalkEntry is the row of one check
+type CrosswalkEntry struct {
+	Title         string              `yaml:"title" json:"title"`
+	Description   string              `yaml:"description" json:"description,omitempty"`
+	Severity      string              `yaml:"severity" json:"severity,omitempty"`
+	Remediation   string              `yaml:"remediation" json:"remediation,omitempty"`
+	ResourceTypes []string            `yaml:"resource_types" json:"resource_types,omitempty"` // e.g. aws_s3_bucket
+	References    []string            `yaml:"references" json:"references,omitempty"`         // documentation URLs
+	Controls      map[string][]string `yaml:"controls" json:"controls"`                       // framework ID to control IDs
+
+	// Control is reported for checks mapped to no framework, such as those
+	// of custom policies written for a single standard
+	Control string `yaml:"control" json:"control,omitempty"`
+}
+
+// ControlRef is a control of one framework
+type ControlRef struct {
+	Framework string `json:"framework"`
+	Control   string `json:"control"`
+}
+
+// ParseCrosswalk parses a crosswalk.yaml document, which overrides the
+// metadata of the checks it lists:
+//
+//	checks:
+//	  KILN-S3-001:
+//	    severity: high
+//	    controls:
+//	      soc2: [CC6.1]
+func ParseCrosswalk(content []byte) (Crosswalk, error) {
+	var doc struct {
+		Checks Crosswalk `yaml:"checks"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	for id, entry := range doc.Checks {
+		if entry.Severity != "" && !containsString(severities, entry.Severity) {
+			return nil, fmt.Errorf("check %s: invalid severity %q: expected critical, high, medium or low", id, entry.Severity)
+		}
+		for framework := range entry.Controls {
+			if _, err := LookupFramework(framework); err != nil {
+				return nil, fmt.Errorf("check %s: %w", id, err)
+			}
+		}
+	}
+	return doc.Checks, nil
+}
+
+// Merge merges the rows of other over c. Fields set in other replace those
+// in c, and controls are replaced per framework, so a row can remap one
+// framework's controls without repeating the rest.
+func (c Crosswalk) Merge(other Crosswalk) {
+	for id, entry := range other {
+		merged, ok := c[id]
+		if !ok {
+			c[id] = entry
+			continue
+		}
+
+		for _, field := range []struct {
+			to   *string
+			from string
+		}{
+			{&merged.Title, entry.Title},
+			{&merged.Description, entry.Description},
+			{&merged.Severity, entry.Severity},
+			{&merged.Remediation, entry.Remediation},
+			{&merged.Control, entry.Control},
+		} {
+			if field.from != "" {
+				*field.to = field.from
+			}
+		}
+		if len(entry.ResourceTypes) > 0 {
+			merged.ResourceTypes = entry.ResourceTypes
+		}
+		if len(entry.References) > 0 {
+			merged.References = entry.References
+		}
+		if len(entry.Controls) > 0 {
+			controls := make(map[string][]string, len(merged.Controls)+len(entry.Controls))
+			for framework, ids := range merged.Controls {
+				controls[framework] = ids
+			}
+			for framework, ids := range entry.Controls {
+				controls[framework] = ids
+			}
+			merged.Controls = controls
+		}
+		c[id] = merged
+	}
+}
+
+// Controls returns the controls a check is mapped to, in framework order
+func (c Crosswalk) Controls(checkID string) []ControlRef {
+	entry, ok := c[checkID]
+	if !ok {
+		return nil
+	}
+
+	var refs []ControlRef
+	for _, f := range frameworks {
+		for _, control := range entry.Controls[f.ID] {
+			refs = append(refs, ControlRef{Framework: f.ID, Control: control})
+		}
+	}
+	return refs
+}
+
+// ControlsIn returns the controls of one framework the finding's check is
+// mapped to, e.g. [164.312(b) 164.312(c)(1)] for hipaa
+func (f Finding) ControlsIn(framework string) []string {
+	var controls []string
+	for _, ref := range f.Controls {
+		if ref.Framework == framework {
+			controls = append(controls, ref.Control)
+		}
+	}
+	return controls
+}
+
+// Pivot returns the findings of r reported against one framework: each
+// finding's Control is that framework's controls, and findings of checks not
+// mapped to it are left out. Only scanned findings are pivoted, so scan with
+// the framework selected to see every check it maps.
+func (r *Result) Pivot(framework string) (*Result, error) {
+	f, err := LookupFramework(framework)
+	if err != nil {
+		return nil, err
+	}
+
+	pivoted := &Result{
+		Errors:           r.Errors,
+		ScannedAt:        r.ScannedAt,
+		Exceptions:       r.Exceptions,
+		BaselineResolved: r.BaselineResolved,
+	}
+	for _, set := range []struct {
+		from []Finding
+		to   *[]Finding
+	}{
+		{r.Violations, &pivoted.Violations},
+		{r.Warnings, &pivoted.Warnings},
+		{r.Passed, &pivoted.Passed},
+		{r.Suppressed, &pivoted.Suppressed},
+		{r.Baselined, &pivoted.Baselined},
+	} {
+		*set.to = []Finding{}
+		for _, finding := range set.from {
+			if controls := finding.ControlsIn(f.ID); len(controls) > 0 {
+				finding.Control = strings.Join(controls, ", ")
+				finding.Framework = f.ID
+				*set.to = append(*set.to, finding)
+			}
+		}
+	}
+
+	// Keep the framework's score from the scan, which also counts
+	// baselined findings
+	score := FrameworkScore{Framework: f.ID, Name: f.Name}
+	score.tally(pivoted, func(Finding) bool { return true })
+	for _, s := range r.Frameworks {
+		if s.Framework == f.ID {
+			score = s
+		}
+	}
+	pivoted.Score = score.Score
+	pivoted.Frameworks = []FrameworkScore{score}
+	return pivoted, nil
+}
+
+// scorePercent is the share of passed findings, 0 to 100
+func scorePercent(violations, warnings, passed int) int {
+	total := violations + warnings + passed
+	if total == 0 {
+		return 0
+	}
+	return (passed * 100) / total
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// driftControl is the control drift is reported under until the findings
+// are mapped through the crosswalk: changes that bypass code review undermine
+// change management
+const driftControl = "CC8.1"
+
+// Check IDs of drift findings
+const (
+	driftSettingCheck   = "KILN-DRIFT-001" // security setting changed outside Terraform
+	driftRuleCheck      = "KILN-DRIFT-002" // security group rule added outside Terraform
+	driftUnmanagedCheck = "KILN-DRIFT-003" // resource in state without configuration
+)
+
+// driftSettings are the security-relevant settings compared between
+// configuration and state, by resource type. Nested block settings are
+// written as paths, e.g. rule.apply_server_side_encryption_by_default.sse_algorithm.
+var driftSettings = map[string][]string{
+	"aws_db_instance":     {"storage_encrypted", "publicly_accessible", "iam_database_authentication_enabled", "deletion_protection"},
+	"aws_rds_cluster":     {"storage_encrypted", "deletion_protection"},
+	"aws_ebs_volume":      {"encrypted"},
+	"aws_efs_file_system": {"encrypted"},
+	"aws_kms_key":         {"enable_key_rotation"},
+	"aws_cloudtrail":      {"enable_logging", "is_multi_region_trail", "enable_log_file_validation"},
+	"aws_lb_listener":     {"protocol", "ssl_policy"},
+	"aws_alb_listener":    {"protocol", "ssl_policy"},
+	"aws_s3_bucket": {
+		"server_side_encryption_configuration.rule.apply_server_side_encryption_by_default.sse_algorithm",
+		"server_side_encryption_configuration.rule.apply_server_side_encryption_by_default.kms_master_key_id",
+		"versioning.enabled",
+	},
+	"aws_s3_bucket_server_side_encryption_configuration": {
+		"rule.apply_server_side_encryption_by_default.sse_algorithm",
+	},
+	"aws_s3_bucket_public_access_block": {"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"},
+	"aws_s3_bucket_versioning":          {"versioning_configuration.status"},
+}
+
+// securityGroupRuleTypes are standalone rule resources; one in state without
+// configuration is a rule added out-of-band
+var securityGroupRuleTypes = map[string]bool{
+	"aws_security_group_rule":             true,
+	"aws_vpc_security_group_ingress_rule": true,
+	"aws_vpc_security_group_egress_rule":  true,
+}
+
+// instanceKeyPattern matches the instance key at the end of an address
+var instanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]$`)
+
+// CompareState lines up resources in configuration and state by address and
+// reports security-relevant drift as findings under CC8.1: settings that
+// differ from the code, security group rules added outside Terraform, and
+// resources that exist in state without configuration. Resources that match
+// their configuration are reported as passed, and configuration that could
+// not be parsed is carried over to Errors. Drift in blocks with a kiln:ignore
+// comment is set aside in Suppressed. OPAEvaluator.MapFindings reports the
+// findings against other frameworks.
+func CompareState(config, state *TerraformData) *Result {
+	result := &Result{
+		Violations: []Finding{},
+		Warnings:   []Finding{},
+		Passed:     []Finding{},
+		Suppressed: []Finding{},
+		Errors:     append([]ScanError{}, config.Errors...),
+		ScannedAt:  time.Now().Format(time.RFC3339),
+	}
+
+	configModules := make(map[string]bool)
+	for _, r := range config.Resources {
+		configModules[moduleOf(r.Address)] = true
+	}
+
+	for _, actual := range state.Resources {
+		expected := configResource(config, actual.Address)
+		if expected == nil {
+			// A module missing from configuration couldn't be loaded (e.g. a
+			// registry module before terraform init), so its resources can't
+			// be told apart from unmanaged ones
+			if !configModules[moduleOf(actual.Address)] {
+				continue
+			}
+			finding := unmanagedFinding(actual)
+			if securityGroupRuleTypes[actual.Type] {
+				finding.CheckID = driftRuleCheck
+				finding.Severity = "high"
+				finding.Message = fmt.Sprintf("Security group rule '%s' was added outside Terraform", actual.Address)
+				result.Violations = append(result.Violations, finding)
+			} else {
+				result.Warnings = append(result.Warnings, finding)
+			}
+			continue
+		}
+
+		drifts := settingDrift(*expected, actual)
+		drifts = append(drifts, securityGroupDrift(*expected, actual)...)
+
+		if len(drifts) == 0 {
+			result.Passed = append(result.Passed, driftFinding(*expected, driftSettingCheck, "low",
+				fmt.Sprintf("Resource '%s' matches its configuration", actual.Address), ""))
+			continue
+		}
+		result.Violations = append(result.Violations, drifts...)
+	}
+
+	applySuppressions(result, config.Suppressions, time.Now())
+
+	total := len(result.Violations) + len(result.Warnings) + len(result.Passed)
+	if total > 0 {
+		result.Score = (len(result.Passed) * 100) / total
+	}
+	attachFingerprints(result)
+
+	return result
+}
+
+// configResource finds the configuration for a resource in state. Resources
+// whose count or for_each couldn't be evaluated statically are configured
+// once, without an instance key.
+func configResource(config *TerraformData, address string) *Resource {
+	for i := range config.Resources {
+		if config.Resources[i].Address == address {
+			return &config.Resources[i]
+		}
+	}
+
+	base := instanceKeyPattern.ReplaceAllString(address, "")
+	if base == address {
+		return nil
+	}
+	for i := range config.Resources {
+		if config.Resources[i].Address == base && config.Resources[i].Index == nil {
+			return &config.Resources[i]
+		}
+	}
+	return nil
+}
+
+// moduleOf returns the module part of a resource address, empty for the root
+func moduleOf(address string) string {
+	parts := strings.Split(address, ".")
+	end := 0
+	for i := 0; i+1 < len(parts); i += 2 {
+		if parts[i] != "module" {
+			break
+		}
+		end = i + 2
+	}
+	return strings.Join(parts[:end], ".")
+}
+
+// settingDrift compares the security settings of a resource. Settings the
+// configuration leaves unset, or sets from values only known after apply,
+// aren't compared.
+func settingDrift(expected, actual Resource) []Finding {
+	var findings []Finding
+	for _, path := range driftSettings[actual.Type] {
+		want, ok := settingValue(expected.Config, path)
+		if !ok || !comparableSetting(want) {
+			continue
+		}
+		got, _ := settingValue(actual.Config, path)
+		if fmt.Sprint(want) == fmt.Sprint(got) {
+			continue
+		}
+
+		findings = append(findings, driftFinding(expected, driftSettingCheck, "high",
+			fmt.Sprintf("Resource '%s' has %s = %s in state but %s in configuration",
+				actual.Address, path, settingString(got), settingString(want)),
+			"Find out who changed it and why, then re-apply the configuration or update it to match through code review"))
+	}
+	return findings
+}
+
+// securityGroupDrift reports ingress and egress rules in state that the
+// configuration doesn't declare. Security groups whose rules are managed by
+// separate rule resources, or can't be resolved statically, are skipped.
+func securityGroupDrift(expected, actual Resource) []Finding {
+	if actual.Type != "aws_security_group" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, direction := range []string{"ingress", "egress"} {
+		value, declared := expected.Config[direction]
+		if !declared {
+			continue
+		}
+		if _, dynamic := expected.Config["dynamic"]; dynamic {
+			continue
+		}
+
+		declaredRules := make(map[string]bool)
+		comparable := true
+		for _, rule := range blockList(value) {
+			key, ok := ruleKey(rule)
+			if !ok {
+				comparable = false
+				break
+			}
+			declaredRules[key] = true
+		}
+		if !comparable {
+			continue
+		}
+
+		for _, rule := range blockList(actual.Config[direction]) {
+			key, ok := ruleKey(rule)
+			if !ok || declaredRules[key] {
+				continue
+			}
+			findings = append(findings, driftFinding(expected, driftRuleCheck, "high",
+				fmt.Sprintf("Security group '%s' has an %s rule added outside Terraform: %s", actual.Address, direction, key),
+				"Remove the rule or add it to the configuration through code review"))
+		}
+	}
+	return findings
+}
+
+// ruleKey describes a security group rule, e.g. "tcp 22-22 from 0.0.0.0/0".
+// It reports false when the rule holds values that aren't resolved.
+func ruleKey(rule map[string]interface{}) (string, bool) {
+	protocol, ok := rule["protocol"].(string)
+	if !ok {
+		return "", false
+	}
+	protocol = strings.ToLower(protocol)
+	if protocol == "all" {
+		protocol = "-1"
+	}
+
+	var sources []string
+	for _, attr := range []string{"cidr_blocks", "ipv6_cidr_blocks", "security_groups", "prefix_list_ids"} {
+		value, ok := rule[attr]
+		if !ok {
+			continue
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", false
+		}
+		for _, item := range items {
+			// State restores references to other resources, so they
+			// compare as text
+			s, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			sources = append(sources, s)
+		}
+	}
+	if self, _ := rule["self"].(bool); self {
+		sources = append(sources, "self")
+	}
+	if len(sources) == 0 {
+		return "", false
+	}
+	sort.Strings(sources)
+
+	for _, port := range []string{"from_port", "to_port"} {
+		if _, ok := rule[port].(float64); !ok {
+			return "", false
+		}
+	}
+
+	return fmt.Sprintf("%s %v-%v from %s", protocol, rule["from_port"], rule["to_port"], strings.Join(sources, ",")), true
+}
+
+// settingValue looks up a dotted path, descending into the first of a
+// nested block's instances
+func settingValue(config map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = config
+	for _, key := range strings.Split(path, ".") {
+		blocks := blockList(value)
+		if len(blocks) == 0 {
+			return nil, false
+		}
+		next, ok := blocks[0][key]
+		if !ok {
+			return nil, false
+		}
+		value = next
+	}
+	return value, true
+}
+
+// blockList normalizes a nested block, which is an object when declared once
+// and an array when repeated, to a list of objects
+func blockList(value interface{}) []map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []interface{}:
+		var blocks []map[string]interface{}
+		for _, item := range v {
+			if block, ok := item.(map[string]interface{}); ok {
+				blocks = append(blocks, block)
+			}
+		}
+		return blocks
+	}
+	return nil
+}
+
+// comparableSetting reports whether a configured value is known statically
+func comparableSetting(value interface{}) bool {
+	switch v := value.(type) {
+	case bool, float64:
+		return true
+	case string:
+		return !isReferenceText(v)
+	}
+	return false
+}
+
+// isReferenceText reports whether a configured string is the text of an
+// unresolved reference, such as aws_kms_key.main.arn or var.kms_key
+func isReferenceText(s string) bool {
+	if strings.ContainsAny(s, " /:") || !strings.Contains(s, ".") {
+		return false
+	}
+	root := strings.SplitN(s, ".", 2)[0]
+	switch root {
+	case "var", "local", "module", "data":
+		return true
+	}
+	return isResourceReference(s)
+}
+
+// settingString renders a setting for a drift message
+func settingString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "unset"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(value)
+}
+
+// unmanagedFinding reports a resource that exists in state only
+func unmanagedFinding(r Resource) Finding {
+	return Finding{
+		CheckID:     driftUnmanagedCheck,
+		Control:     driftControl,
+		Severity:    "medium",
+		Resource:    r.Address,
+		Message:     fmt.Sprintf("Resource '%s' exists in state but not in configuration", r.Address),
+		Remediation: "Bring the resource under code (terraform import with a matching resource block) or remove it from state",
+		File:        r.File,
+	}
+}
+
+// driftFinding builds a finding located at the resource's configuration
+func driftFinding(r Resource, checkID, severity, message, remediation string) Finding {
+	finding := Finding{
+		CheckID:     checkID,
+		Control:     driftControl,
+		Severity:    severity,
+		Resource:    r.Address,
+		Message:     message,
+		Remediation: remediation,
+		File:        r.File,
+	}
+	if r.Range != nil {
+		finding.StartLine = r.Range.StartLine
+		finding.StartColumn = r.Range.StartColumn
+		finding.EndLine = r.Range.EndLine
+	}
+	return finding
+}
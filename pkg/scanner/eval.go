@@ -0,0 +1,360 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// ParseOptions controls how variables are resolved while parsing
+type ParseOptions struct {
+	// VarFiles are additional .tfvars files, applied in order after
+	// terraform.tfvars and *.auto.tfvars (like -var-file)
+	VarFiles []string
+
+	// Vars are individual variable values, applied last (like -var)
+	Vars map[string]string
+}
+
+// variableDecl is a variable block as declared in configuration
+type variableDecl struct {
+	name     string
+	typ      cty.Type
+	hasType  bool
+	defValue cty.Value
+}
+
+// maxLocalPasses bounds how many times locals are re-evaluated while
+// resolving references between them
+const maxLocalPasses = 10
+
+// buildEvalContext resolves variables and locals for a module. Root modules
+// take values from tfvars files and options; child modules from the
+// arguments of the module block that calls them.
+func buildEvalContext(variables []*hcl.Block, locals []*hcl.Block, mod moduleContext, opts ParseOptions) (*hcl.EvalContext, error) {
+	decls := make(map[string]*variableDecl)
+	for _, block := range variables {
+		if len(block.Labels) == 0 {
+			continue
+		}
+		decls[block.Labels[0]] = decodeVariable(block)
+	}
+
+	values := make(map[string]cty.Value)
+	for name, decl := range decls {
+		values[name] = decl.defValue
+	}
+
+	if mod.inputs != nil {
+		for name, val := range mod.inputs {
+			if _, declared := decls[name]; declared {
+				values[name] = val
+			}
+		}
+	} else if err := applyRootVariables(values, decls, mod.dir, opts); err != nil {
+		return nil, err
+	}
+
+	// Apply declared type constraints
+	for name, decl := range decls {
+		if !decl.hasType || values[name].IsNull() || !values[name].IsKnown() {
+			continue
+		}
+		if converted, err := convert.Convert(values[name], decl.typ); err == nil {
+			values[name] = converted
+		}
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(values),
+			"local": cty.EmptyObjectVal,
+			"path": cty.ObjectVal(map[string]cty.Value{
+				"module": cty.StringVal(mod.dir),
+				"root":   cty.StringVal(mod.rootDir),
+				"cwd":    cty.StringVal("."),
+			}),
+		},
+		Functions: terraformFunctions(),
+	}
+
+	ctx.Variables["local"] = evaluateLocals(locals, ctx)
+
+	return ctx, nil
+}
+
+// applyRootVariables layers terraform.tfvars, *.auto.tfvars, --var-file and
+// --var values over variable defaults, in the same order as Terraform
+func applyRootVariables(values map[string]cty.Value, decls map[string]*variableDecl, dir string, opts ParseOptions) error {
+	tfvarsFiles := autoVarFiles(dir)
+	tfvarsFiles = append(tfvarsFiles, opts.VarFiles...)
+
+	for _, path := range tfvarsFiles {
+		fileValues, err := loadVarFile(path)
+		if err != nil {
+			return err
+		}
+		for name, val := range fileValues {
+			if _, declared := decls[name]; declared {
+				values[name] = val
+			}
+		}
+	}
+
+	for name, raw := range opts.Vars {
+		decl, declared := decls[name]
+		if !declared {
+			continue
+		}
+		val, err := parseVarValue(raw, decl)
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+		values[name] = val
+	}
+
+	return nil
+}
+
+// decodeVariable reads the type and default of a variable block. Variables
+// without a default are unknown until a value is supplied.
+func decodeVariable(block *hcl.Block) *variableDecl {
+	decl := &variableDecl{
+		name:     block.Labels[0],
+		typ:      cty.DynamicPseudoType,
+		defValue: cty.DynamicVal,
+	}
+
+	attrs, _ := block.Body.JustAttributes()
+
+	if attr, ok := attrs["type"]; ok {
+		if ty, diags := typeexpr.TypeConstraint(attr.Expr); !diags.HasErrors() {
+			decl.typ = ty
+			decl.hasType = true
+		}
+	}
+
+	if attr, ok := attrs["default"]; ok {
+		if val, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+			decl.defValue = val
+		}
+	}
+
+	return decl
+}
+
+// evaluateLocals resolves locals blocks, re-evaluating until references
+// between locals settle. Locals that depend on resources stay unknown.
+func evaluateLocals(blocks []*hcl.Block, ctx *hcl.EvalContext) cty.Value {
+	attrs := make(map[string]*hcl.Attribute)
+	for _, block := range blocks {
+		blockAttrs, _ := block.Body.JustAttributes()
+		for name, attr := range blockAttrs {
+			attrs[name] = attr
+		}
+	}
+
+	values := make(map[string]cty.Value)
+	for name := range attrs {
+		values[name] = cty.DynamicVal
+	}
+
+	for pass := 0; pass < maxLocalPasses; pass++ {
+		ctx.Variables["local"] = cty.ObjectVal(values)
+		changed := false
+
+		for name, attr := range attrs {
+			if values[name].IsWhollyKnown() {
+				continue
+			}
+			val, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() || !val.IsWhollyKnown() {
+				continue
+			}
+			values[name] = val
+			changed = true
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return cty.ObjectVal(values)
+}
+
+// autoVarFiles returns the tfvars files Terraform loads automatically from dir
+func autoVarFiles(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+
+	var files []string
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+
+	var auto []string
+	for _, pattern := range []string{"*.auto.tfvars", "*.auto.tfvars.json"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		auto = append(auto, matches...)
+	}
+	sort.Strings(auto)
+
+	return append(files, auto...)
+}
+
+// loadVarFile reads variable values from a .tfvars or .tfvars.json file
+func loadVarFile(path string) (map[string]cty.Value, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read var file: %w", err)
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = parser.ParseJSON(content, path)
+	} else {
+		file, diags = parser.ParseHCL(content, path)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse var file: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse var file: %s", diags.Error())
+	}
+
+	values := make(map[string]cty.Value)
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("var file %s: %s", path, diags.Error())
+		}
+		values[name] = val
+	}
+
+	return values, nil
+}
+
+// parseVarValue interprets a -var value. Like Terraform, untyped and string
+// variables take the raw text and everything else is parsed as an HCL expression.
+func parseVarValue(raw string, decl *variableDecl) (cty.Value, error) {
+	if !decl.hasType || decl.typ == cty.String {
+		return cty.StringVal(raw), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), "<value for var."+decl.name+">", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("%s", diags.Error())
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("%s", diags.Error())
+	}
+
+	return val, nil
+}
+
+// terraformFunctions returns the subset of Terraform's built-in functions
+// that can be evaluated statically
+func terraformFunctions() map[string]function.Function {
+	return map[string]function.Function{
+		"abs":        stdlib.AbsoluteFunc,
+		"ceil":       stdlib.CeilFunc,
+		"chomp":      stdlib.ChompFunc,
+		"coalesce":   stdlib.CoalesceFunc,
+		"compact":    stdlib.CompactFunc,
+		"concat":     stdlib.ConcatFunc,
+		"contains":   stdlib.ContainsFunc,
+		"distinct":   stdlib.DistinctFunc,
+		"element":    stdlib.ElementFunc,
+		"flatten":    stdlib.FlattenFunc,
+		"floor":      stdlib.FloorFunc,
+		"format":     stdlib.FormatFunc,
+		"formatlist": stdlib.FormatListFunc,
+		"indent":     stdlib.IndentFunc,
+		"join":       stdlib.JoinFunc,
+		"jsondecode": stdlib.JSONDecodeFunc,
+		"jsonencode": stdlib.JSONEncodeFunc,
+		"keys":       stdlib.KeysFunc,
+		"length":     stdlib.LengthFunc,
+		"lookup":     stdlib.LookupFunc,
+		"lower":      stdlib.LowerFunc,
+		"max":        stdlib.MaxFunc,
+		"merge":      stdlib.MergeFunc,
+		"min":        stdlib.MinFunc,
+		"range":      stdlib.RangeFunc,
+		"regex":      stdlib.RegexFunc,
+		"replace":    stdlib.ReplaceFunc,
+		"reverse":    stdlib.ReverseListFunc,
+		"setunion":   stdlib.SetUnionFunc,
+		"slice":      stdlib.SliceFunc,
+		"sort":       stdlib.SortFunc,
+		"split":      stdlib.SplitFunc,
+		"substr":     stdlib.SubstrFunc,
+		"title":      stdlib.TitleFunc,
+		"tolist":     makeToFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":      makeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber":   makeToFunc(cty.Number),
+		"toset":      makeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring":   makeToFunc(cty.String),
+		"trimspace":  stdlib.TrimSpaceFunc,
+		"upper":      stdlib.UpperFunc,
+		"values":     stdlib.ValuesFunc,
+		"zipmap":     stdlib.ZipmapFunc,
+	}
+}
+
+// makeToFunc builds a type conversion function such as tostring or tolist
+func makeToFunc(wantTy cty.Type) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name:             "v",
+				Type:             cty.DynamicPseudoType,
+				AllowNull:        true,
+				AllowDynamicType: true,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			gotTy := args[0].Type()
+			if gotTy.Equals(wantTy) {
+				return wantTy, nil
+			}
+			conv := convert.GetConversionUnsafe(gotTy, wantTy)
+			if conv == nil {
+				return cty.NilType, fmt.Errorf("cannot convert %s to %s", gotTy.FriendlyName(), wantTy.FriendlyNameForConstraint())
+			}
+			out, err := conv(cty.UnknownVal(gotTy))
+			if err != nil {
+				return cty.NilType, err
+			}
+			return out.Type(), nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return convert.Convert(args[0], retType)
+		},
+	})
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExceptionsFile is the name of the repository's exceptions file, found by
+// FindExceptionsFile
+const ExceptionsFile = "kiln-exceptions.yaml"
+
+// exceptionExpiryWarning is how long before its expiry an exception is
+// reported as expiring
+const exceptionExpiryWarning = 30 * 24 * time.Hour
+
+// Exception statuses
+const (
+	ExceptionActive   = "active"   // applied to at least one finding
+	ExceptionExpiring = "expiring" // applied, but expires within 30 days
+	ExceptionExpired  = "expired"  // past its expiry; its findings are reported again
+	ExceptionStale    = "stale"    // matches no finding and can be removed
+)
+
+// Exception is an approved risk acceptance in the exceptions file. It
+// covers a finding by fingerprint, or the findings of a check on the
+// resources matching a glob.
+type Exception struct {
+	Fingerprint string `yaml:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	Check       string `yaml:"check,omitempty" json:"check,omitempty"`       // check ID, may use * wildcards
+	Resource    string `yaml:"resource,omitempty" json:"resource,omitempty"` // resource address glob, default *
+	Reason      string `yaml:"reason,omitempty" json:"reason,omitempty"`
+	Owner       string `yaml:"owner" json:"owner"`       // who is responsible for the risk
+	Approver    string `yaml:"approver" json:"approver"` // who accepted it
+	Ticket      string `yaml:"ticket" json:"ticket"`     // link to the approval
+	Expires     string `yaml:"expires" json:"expires"`   // e.g. 2027-01-01
+
+	// Expired is set on the findings of an expired exception, which are
+	// reported again
+	Expired bool `yaml:"-" json:"expired,omitempty"`
+}
+
+// ExceptionStatus is the state of an exception after a scan, for the
+// exceptions appendix of reports
+type ExceptionStatus struct {
+	Exception
+	Status   string   `json:"status"`   // one of the Exception statuses
+	Findings []string `json:"findings"` // fingerprints of the findings it matched
+}
+
+// ParseExceptions parses an exceptions file:
+//
+//	exceptions:
+//	  - check: KILN-S3-001
+//	    resource: aws_s3_bucket.legacy_*
+//	    reason: Read-only archive, replaced in Q3
+//	    owner: platform@example.com
+//	    approver: security@example.com
+//	    ticket: https://tracker.example.com/SEC-142
+//	    expires: 2027-01-01
+func ParseExceptions(content []byte) ([]Exception, error) {
+	var doc struct {
+		Exceptions []Exception `yaml:"exceptions"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	for i, e := range doc.Exceptions {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("exception %d: %w", i+1, err)
+		}
+	}
+	return doc.Exceptions, nil
+}
+
+// LoadExceptions reads and parses the exceptions file at path
+func LoadExceptions(path string) ([]Exception, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := ParseExceptions(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return exceptions, nil
+}
+
+// FindExceptionsFile looks for kiln-exceptions.yaml in the directory of
+// path and its parents, stopping at the root of the repository. It returns
+// "" when there is none.
+func FindExceptionsFile(path string) string {
+	return findUp(path, ExceptionsFile)
+}
+
+// findUp looks for a file named name in dir, or the directory of a file,
+// and its parents up to the first one containing .git
+func findUp(path, name string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// validate checks that an exception selects findings and records who
+// approved it, where and until when
+func (e Exception) validate() error {
+	if e.Fingerprint == "" && e.Check == "" {
+		return fmt.Errorf("needs a fingerprint or a check")
+	}
+	if e.Fingerprint != "" && (e.Check != "" || e.Resource != "") {
+		return fmt.Errorf("fingerprint %s: a fingerprint already selects one finding; drop check and resource", e.Fingerprint)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"owner", e.Owner},
+		{"approver", e.Approver},
+		{"ticket", e.Ticket},
+		{"expires", e.Expires},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%s: %s is required", e.label(), field.name)
+		}
+	}
+	if _, err := time.Parse(time.DateOnly, e.Expires); err != nil {
+		return fmt.Errorf("%s: expires must be a date like 2027-01-01, got %q", e.label(), e.Expires)
+	}
+	return nil
+}
+
+// label names an exception in messages, by fingerprint or check and resource
+func (e Exception) label() string {
+	if e.Fingerprint != "" {
+		return "fingerprint " + e.Fingerprint
+	}
+	if e.Resource != "" {
+		return e.Check + " on " + e.Resource
+	}
+	return e.Check
+}
+
+// ExpiredOn reports whether the exception no longer applies on day
+func (e Exception) ExpiredOn(day time.Time) bool {
+	return day.Format(time.DateOnly) >= e.Expires
+}
+
+// matches reports whether the exception covers a finding
+func (e Exception) matches(f Finding) bool {
+	if e.Fingerprint != "" {
+		return strings.EqualFold(e.Fingerprint, f.Fingerprint)
+	}
+	resource := e.Resource
+	if resource == "" {
+		resource = "*"
+	}
+	return f.CheckID != "" && globMatch(e.Check, f.CheckID) && globMatch(resource, f.Resource)
+}
+
+// ApplyExceptions moves the violations and warnings covered by an exception
+// to Suppressed and rescores the result. Findings of expired exceptions
+// stay where they are, with the exception attached. It returns the status
+// of every exception, as also recorded in r.Exceptions.
+func (r *Result) ApplyExceptions(exceptions []Exception, now time.Time) []ExceptionStatus {
+	statuses := make([]ExceptionStatus, len(exceptions))
+	for i, e := range exceptions {
+		statuses[i] = ExceptionStatus{Exception: e, Findings: []string{}}
+	}
+	if r.Suppressed == nil {
+		r.Suppressed = []Finding{}
+	}
+
+	// Match the findings already suppressed inline too, so that an exception
+	// repeating a kiln:ignore comment isn't reported as stale
+	for _, f := range r.Suppressed {
+		for i, e := range exceptions {
+			if e.matches(f) {
+				statuses[i].Findings = append(statuses[i].Findings, f.Fingerprint)
+				break
+			}
+		}
+	}
+
+	for _, findings := range []*[]Finding{&r.Violations, &r.Warnings} {
+		active := (*findings)[:0]
+		for _, f := range *findings {
+			for i, e := range exceptions {
+				if e.matches(f) {
+					e.Expired = e.ExpiredOn(now)
+					f.Exception = &e
+					statuses[i].Findings = append(statuses[i].Findings, f.Fingerprint)
+					break
+				}
+			}
+
+			if f.Exception != nil && !f.Exception.Expired {
+				r.Suppressed = append(r.Suppressed, f)
+				continue
+			}
+			active = append(active, f)
+		}
+		*findings = active
+	}
+
+	for i := range statuses {
+		s := &statuses[i]
+		switch {
+		case s.ExpiredOn(now):
+			s.Status = ExceptionExpired
+		case len(s.Findings) == 0:
+			s.Status = ExceptionStale
+		case s.ExpiredOn(now.Add(exceptionExpiryWarning)):
+			s.Status = ExceptionExpiring
+		default:
+			s.Status = ExceptionActive
+		}
+	}
+
+	r.Exceptions = statuses
+	r.rescore()
+	return statuses
+}
+
+// Warning describes what needs doing about an exception that is expiring,
+// expired or stale, or is "" for an active one
+func (s ExceptionStatus) Warning() string {
+	switch s.Status {
+	case ExceptionExpiring:
+		return fmt.Sprintf("exception for %s expires on %s (owner %s, %s)", s.label(), s.Expires, s.Owner, s.Ticket)
+	case ExceptionExpired:
+		return fmt.Sprintf("exception for %s expired on %s; its findings are reported again (owner %s, %s)", s.label(), s.Expires, s.Owner, s.Ticket)
+	case ExceptionStale:
+		return fmt.Sprintf("exception for %s matches no finding and can be removed (%s)", s.label(), s.Ticket)
+	}
+	return ""
+}
+
+// rescore recomputes the score and framework scores after findings moved
+// between sets
+func (r *Result) rescore() {
+	r.Score = scorePercent(len(r.Violations), len(r.Warnings), len(r.Passed))
+	for i, s := range r.Frameworks {
+		score := FrameworkScore{Framework: s.Framework, Name: s.Name}
+		if _, err := LookupFramework(s.Framework); err == nil {
+			score.tally(r, func(f Finding) bool { return len(f.ControlsIn(s.Framework)) > 0 })
+		} else {
+			score.tally(r, func(f Finding) bool { return len(f.Controls) == 0 && f.Package == s.Framework })
+		}
+		r.Frameworks[i] = score
+	}
+}
+
+// globMatch matches s against a pattern in which * stands for any run of
+// characters. Resource addresses contain brackets and dots, so path.Match
+// doesn't fit.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExceptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name: "by check and resource",
+			content: `exceptions:
+  - check: KILN-S3-*
+    resource: aws_s3_bucket.legacy_*
+    owner: platform@example.com
+    approver: security@example.com
+    ticket: SEC-142
+    expires: 2027-01-01
+`,
+			want: 1,
+		},
+		{
+			name: "by fingerprint",
+			content: `exceptions:
+  - fingerprint: 0123456789abcdef
+    owner: platform@example.com
+    approver: security@example.com
+    ticket: SEC-142
+    expires: 2027-01-01
+`,
+			want: 1,
+		},
+		{
+			name: "no selector",
+			content: `exceptions:
+  - owner: platform@example.com
+    approver: security@example.com
+    ticket: SEC-142
+    expires: 2027-01-01
+`,
+			wantErr: "exception 1: needs a fingerprint or a check",
+		},
+		{
+			name: "fingerprint and check",
+			content: `exceptions:
+  - fingerprint: 0123456789abcdef
+    check: KILN-S3-001
+    owner: platform@example.com
+    approver: security@example.com
+    ticket: SEC-142
+    expires: 2027-01-01
+`,
+			wantErr: "drop check and resource",
+		},
+		{
+			name: "missing approver",
+			content: `exceptions:
+  - check: KILN-S3-001
+    owner: platform@example.com
+    ticket: SEC-142
+    expires: 2027-01-01
+`,
+			wantErr: "KILN-S3-001: approver is required",
+		},
+		{
+			name: "invalid expiry",
+			content: `exceptions:
+  - check: KILN-S3-001
+    owner: platform@example.com
+    approver: security@example.com
+    ticket: SEC-142
+    expires: soon
+`,
+			wantErr: `expires must be a date like 2027-01-01, got "soon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExceptions([]byte(tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d exceptions, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyExceptions(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	findings := func() []Finding {
+		return []Finding{
+			{CheckID: "KILN-S3-001", Resource: "aws_s3_bucket.legacy_logs", Fingerprint: "aaaa"},
+			{CheckID: "KILN-S3-002", Resource: "aws_s3_bucket.legacy_logs", Fingerprint: "bbbb"},
+			{CheckID: "KILN-S3-001", Resource: "aws_s3_bucket.current", Fingerprint: "cccc"},
+		}
+	}
+	exception := func(e Exception) Exception {
+		e.Owner, e.Approver, e.Ticket = "platform@example.com", "security@example.com", "SEC-142"
+		if e.Expires == "" {
+			e.Expires = "2027-01-01"
+		}
+		return e
+	}
+
+	tests := []struct {
+		name           string
+		exception      Exception
+		wantSuppressed []string // fingerprints
+		wantStatus     string
+	}{
+		{
+			name:           "check on every resource",
+			exception:      exception(Exception{Check: "KILN-S3-001"}),
+			wantSuppressed: []string{"aaaa", "cccc"},
+			wantStatus:     ExceptionActive,
+		},
+		{
+			name:           "check glob and resource glob",
+			exception:      exception(Exception{Check: "KILN-S3-*", Resource: "aws_s3_bucket.legacy_*"}),
+			wantSuppressed: []string{"aaaa", "bbbb"},
+			wantStatus:     ExceptionActive,
+		},
+		{
+			name:           "fingerprint",
+			exception:      exception(Exception{Fingerprint: "CCCC"}),
+			wantSuppressed: []string{"cccc"},
+			wantStatus:     ExceptionActive,
+		},
+		{
+			name:           "expiring within 30 days",
+			exception:      exception(Exception{Check: "KILN-S3-002", Expires: "2026-06-20"}),
+			wantSuppressed: []string{"bbbb"},
+			wantStatus:     ExceptionExpiring,
+		},
+		{
+			name:       "expired",
+			exception:  exception(Exception{Check: "KILN-S3-002", Expires: "2026-06-01"}),
+			wantStatus: ExceptionExpired,
+		},
+		{
+			name:       "stale",
+			exception:  exception(Exception{Check: "KILN-EC2-*"}),
+			wantStatus: ExceptionStale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Violations: findings()}
+			statuses := result.ApplyExceptions([]Exception{tt.exception}, now)
+
+			var suppressed []string
+			for _, f := range result.Suppressed {
+				suppressed = append(suppressed, f.Fingerprint)
+			}
+			if strings.Join(suppressed, ",") != strings.Join(tt.wantSuppressed, ",") {
+				t.Errorf("suppressed = %v, want %v", suppressed, tt.wantSuppressed)
+			}
+			if len(result.Suppressed)+len(result.Violations) != 3 {
+				t.Errorf("findings lost or duplicated: %d violations, %d suppressed", len(result.Violations), len(result.Suppressed))
+			}
+
+			if len(statuses) != 1 || statuses[0].Status != tt.wantStatus {
+				t.Fatalf("statuses = %+v, want one %s", statuses, tt.wantStatus)
+			}
+			if got := statuses[0].Warning(); (got == "") != (tt.wantStatus == ExceptionActive) {
+				t.Errorf("Warning() = %q for a %s exception", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestApplyExceptionsCountsInlineSuppressions(t *testing.T) {
+	result := &Result{
+		Suppressed: []Finding{{CheckID: "KILN-S3-001", Resource: "aws_s3_bucket.a", Fingerprint: "aaaa"}},
+	}
+	statuses := result.ApplyExceptions([]Exception{{
+		Check:    "KILN-S3-001",
+		Owner:    "platform@example.com",
+		Approver: "security@example.com",
+		Ticket:   "SEC-142",
+		Expires:  "2027-01-01",
+	}}, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if statuses[0].Status != ExceptionActive {
+		t.Errorf("status = %s, want %s", statuses[0].Status, ExceptionActive)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"KILN-S3-001", "KILN-S3-001", true},
+		{"KILN-S3-001", "KILN-S3-0011", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"KILN-S3-*", "KILN-S3-001", true},
+		{"KILN-S3-*", "KILN-EC2-001", false},
+		{"*-001", "KILN-S3-001", true},
+		{"KILN-*-001", "KILN-S3-001", true},
+		{"KILN-*-001", "KILN-S3-002", false},
+		{`aws_s3_bucket.fleet["*"]`, `aws_s3_bucket.fleet["logs"]`, true},
+		{`module.*.aws_s3_bucket.this`, `module.logs["a"].aws_s3_bucket.this`, true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+		{"ab*ba", "aba", false},
+	}
+
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
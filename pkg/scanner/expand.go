@@ -0,0 +1,220 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// repetitionSchema picks out the meta-arguments that repeat a resource or module
+var repetitionSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "count"},
+		{Name: "for_each"},
+	},
+}
+
+// maxInstances bounds how many instances count or for_each may expand a
+// block into, so that a mistaken count can't exhaust memory
+const maxInstances = 10000
+
+// instance is one expansion of a resource or module block
+type instance struct {
+	key cty.Value // count index or for_each key; cty.NilVal when not repeated
+	ctx *hcl.EvalContext
+}
+
+// addressKey formats the instance key as it appears in addresses: [0] or ["logs"]
+func (i instance) addressKey() string {
+	if i.key == cty.NilVal {
+		return ""
+	}
+	return formatIndex(i.key)
+}
+
+// index returns the instance key as a Go value, or nil when not repeated
+func (i instance) index() interface{} {
+	if i.key == cty.NilVal {
+		return nil
+	}
+	if i.key.Type() == cty.Number {
+		n, _ := i.key.AsBigFloat().Int64()
+		return int(n)
+	}
+	return i.key.AsString()
+}
+
+// expandInstances evaluates count or for_each on a block. When the value is
+// known statically, one instance is returned per element, each with count or
+// each bound in its context. Otherwise the block is treated as a single
+// instance, as it was written; so is a block with an invalid count or too
+// many instances, which is reported in the diagnostics.
+func expandInstances(body hcl.Body, ctx *hcl.EvalContext) ([]instance, hcl.Diagnostics) {
+	single := []instance{{key: cty.NilVal, ctx: ctx}}
+
+	content, _, _ := body.PartialContent(repetitionSchema)
+	if content == nil {
+		return single, nil
+	}
+
+	if attr, ok := content.Attributes["count"]; ok {
+		val, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.Number {
+			return single, nil
+		}
+
+		f := val.AsBigFloat()
+		if !f.IsInt() || f.Sign() < 0 {
+			return single, instancesError(attr, fmt.Sprintf("count must be a whole number of 0 or more, got %s", f.Text('g', -1)))
+		}
+		n, accuracy := f.Int64()
+		if accuracy != big.Exact || n > maxInstances {
+			return single, instancesError(attr, fmt.Sprintf("count of %s exceeds the limit of %d instances", f.Text('f', 0), maxInstances))
+		}
+		instances := make([]instance, 0, n)
+		for i := int64(0); i < n; i++ {
+			child := ctx.NewChild()
+			child.Variables = map[string]cty.Value{
+				"count": cty.ObjectVal(map[string]cty.Value{
+					"index": cty.NumberIntVal(i),
+				}),
+			}
+			instances = append(instances, instance{key: cty.NumberIntVal(i), ctx: child})
+		}
+		return instances, nil
+	}
+
+	if attr, ok := content.Attributes["for_each"]; ok {
+		val, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+			return single, nil
+		}
+
+		ty := val.Type()
+		if !ty.IsMapType() && !ty.IsObjectType() && !ty.IsSetType() {
+			return single, nil
+		}
+		if n := val.LengthInt(); n > maxInstances {
+			return single, instancesError(attr, fmt.Sprintf("for_each of %d elements exceeds the limit of %d instances", n, maxInstances))
+		}
+
+		var instances []instance
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			if ty.IsSetType() {
+				if v.Type() != cty.String {
+					return single, nil
+				}
+				k = v
+			}
+
+			child := ctx.NewChild()
+			child.Variables = map[string]cty.Value{
+				"each": cty.ObjectVal(map[string]cty.Value{
+					"key":   k,
+					"value": v,
+				}),
+			}
+			instances = append(instances, instance{key: k, ctx: child})
+		}
+		return instances, nil
+	}
+
+	return single, nil
+}
+
+// instancesError reports a count or for_each that can't be expanded
+func instancesError(attr *hcl.Attribute, detail string) hcl.Diagnostics {
+	return hcl.Diagnostics{{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid " + attr.Name,
+		Detail:   detail + "; the block is scanned as a single instance",
+		Subject:  attr.Expr.Range().Ptr(),
+	}}
+}
+
+// expandDynamicBlock renders a dynamic block's content once per element of
+// its for_each. It reports false when for_each isn't known statically.
+func expandDynamicBlock(block *hclsyntax.Block, ctx *hcl.EvalContext, moduleAddress string) ([]map[string]interface{}, bool) {
+	attr, ok := block.Body.Attributes["for_each"]
+	if !ok {
+		return nil, false
+	}
+
+	var content *hclsyntax.Block
+	for _, b := range block.Body.Blocks {
+		if b.Type == "content" {
+			content = b
+			break
+		}
+	}
+	if content == nil {
+		return nil, false
+	}
+
+	// The iterator variable defaults to the block's label
+	iterator := block.Labels[0]
+	if iterAttr, ok := block.Body.Attributes["iterator"]; ok {
+		if name := hcl.ExprAsKeyword(iterAttr.Expr); name != "" {
+			iterator = name
+		}
+	}
+
+	contexts, ok := dynamicContexts(attr.Expr, iterator, ctx)
+	if !ok {
+		return nil, false
+	}
+
+	rendered := make([]map[string]interface{}, 0, len(contexts))
+	for _, child := range contexts {
+		rendered = append(rendered, bodyConfig(content.Body, child, moduleAddress))
+	}
+
+	return rendered, true
+}
+
+// dynamicContexts evaluates a dynamic block's for_each and returns one
+// context per element, with the iterator bound to its key and value
+func dynamicContexts(forEach hcl.Expression, iterator string, ctx *hcl.EvalContext) ([]*hcl.EvalContext, bool) {
+	val, diags := forEach.Value(ctx)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || !val.CanIterateElements() {
+		return nil, false
+	}
+
+	var contexts []*hcl.EvalContext
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+
+		child := ctx.NewChild()
+		child.Variables = map[string]cty.Value{
+			iterator: cty.ObjectVal(map[string]cty.Value{
+				"key":   k,
+				"value": v,
+			}),
+		}
+		contexts = append(contexts, child)
+	}
+
+	return contexts, true
+}
+
+// formatIndex formats an index key: [0] for numbers, ["key"] for strings
+func formatIndex(key cty.Value) string {
+	switch key.Type() {
+	case cty.String:
+		return fmt.Sprintf("[%q]", key.AsString())
+	case cty.Number:
+		bf := key.AsBigFloat()
+		if bf.IsInt() {
+			n, _ := bf.Int(new(big.Int))
+			return fmt.Sprintf("[%s]", n.String())
+		}
+	}
+	return ""
+}
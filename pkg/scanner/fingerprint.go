@@ -0,0 +1,115 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// ComputeFingerprint identifies a finding across scans: a hash of its check,
+// the resource it is about and the file declaring that resource. Messages
+// and line numbers are left out, so rewording a check or editing elsewhere
+// in the file keeps the fingerprint. Findings of custom policies without a
+// check_id fall back to their package, control and message.
+func (f Finding) ComputeFingerprint() string {
+	return f.fingerprint(make(repoRoots))
+}
+
+// fingerprint is ComputeFingerprint, finding repositories through roots
+func (f Finding) fingerprint(roots repoRoots) string {
+	id := f.CheckID
+	if id == "" {
+		id = f.Package + "/" + f.Control + "/" + f.Message
+	}
+	return hashFingerprint(id, f.Resource, roots.path(f.File))
+}
+
+// repoRoots caches the repository root of directories, empty for those
+// outside a repository, so that the findings of a scan look each one up once
+type repoRoots map[string]string
+
+// path is the path of file from the root of the repository holding it, so
+// that scans from any directory or checkout agree. Files outside a
+// repository are identified by their absolute path.
+func (r repoRoots) path(file string) string {
+	if file == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+
+	if root := r.root(filepath.Dir(abs)); root != "" {
+		if rel, err := filepath.Rel(root, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(abs)
+}
+
+// root returns the nearest directory holding .git from dir up, recording
+// the answer for every directory on the way
+func (r repoRoots) root(dir string) string {
+	var visited []string
+	root := ""
+	for {
+		if cached, ok := r[dir]; ok {
+			root = cached
+			break
+		}
+		visited = append(visited, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for _, d := range visited {
+		r[d] = root
+	}
+	return root
+}
+
+// attachFingerprints sets the Fingerprint of every finding of result. When a
+// check reports a resource more than once, e.g. drift in two of its
+// settings, those findings are told apart by their messages.
+func attachFingerprints(result *Result) {
+	sets := [][]Finding{result.Violations, result.Warnings, result.Passed, result.Suppressed}
+
+	roots := make(repoRoots)
+	seen := make(map[string]int)
+	for _, findings := range sets {
+		for i := range findings {
+			findings[i].Fingerprint = findings[i].fingerprint(roots)
+			seen[findings[i].Fingerprint]++
+		}
+	}
+
+	for _, findings := range sets {
+		for i := range findings {
+			if f := &findings[i]; seen[f.Fingerprint] > 1 {
+				f.Fingerprint = hashFingerprint(f.Fingerprint, f.Message)
+			}
+		}
+	}
+}
+
+// hashFingerprint hashes parts into a 16 character fingerprint
+func hashFingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
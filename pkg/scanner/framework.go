@@ -0,0 +1,74 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultFramework is scanned when no framework is selected
+const DefaultFramework = "soc2"
+
+// Framework is a compliance framework whose controls checks are mapped to in
+// the crosswalk
+type Framework struct {
+	ID         string // e.g. cis-aws; also its column in the crosswalk
+	Name       string // full name, e.g. CIS AWS Foundations Benchmark v3.0.0
+	ShortName  string // e.g. CIS AWS
+	Assessment string // what a gap puts at risk, e.g. SOC2 audit
+}
+
+var frameworks = []Framework{
+	{
+		ID:         "soc2",
+		Name:       "SOC2 Trust Service Criteria",
+		ShortName:  "SOC2",
+		Assessment: "SOC2 audit",
+	},
+	{
+		ID:         "iso27001",
+		Name:       "ISO/IEC 27001:2022 Annex A",
+		ShortName:  "ISO 27001",
+		Assessment: "ISO 27001 certification audit",
+	},
+	{
+		ID:         "hipaa",
+		Name:       "HIPAA Security Rule",
+		ShortName:  "HIPAA",
+		Assessment: "HIPAA risk analysis",
+	},
+	{
+		ID:         "pci",
+		Name:       "PCI DSS v4.0",
+		ShortName:  "PCI DSS",
+		Assessment: "PCI DSS assessment",
+	},
+	{
+		ID:         "cis-aws",
+		Name:       "CIS AWS Foundations Benchmark v3.0.0",
+		ShortName:  "CIS AWS",
+		Assessment: "CIS benchmark conformance",
+	},
+}
+
+// Frameworks returns the frameworks kiln maps its checks to
+func Frameworks() []Framework {
+	return append([]Framework(nil), frameworks...)
+}
+
+// LookupFramework finds a framework by ID
+func LookupFramework(id string) (Framework, error) {
+	for _, f := range frameworks {
+		if f.ID == strings.ToLower(id) {
+			return f, nil
+		}
+	}
+
+	ids := make([]string, len(frameworks))
+	for i, f := range frameworks {
+		ids[i] = f.ID
+	}
+	return Framework{}, fmt.Errorf("unknown framework %q (available: %s)", id, strings.Join(ids, ", "))
+}
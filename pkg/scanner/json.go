@@ -0,0 +1,208 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// jsonCommentKey is the property name JSON syntax reserves for comments.
+// CDKTF uses it for construct metadata.
+const jsonCommentKey = "//"
+
+// isJSONFile reports whether a configuration file uses JSON syntax
+// (.tf.json, including the cdk.tf.json that CDKTF synthesizes)
+func isJSONFile(path string) bool {
+	return strings.HasSuffix(path, ".json")
+}
+
+// jsonBodyConfig converts a body written in JSON syntax. Without a schema,
+// nested blocks can't be told apart from object-valued attributes, so every
+// property is walked as a value. A block written as an array holding a
+// single object is stored as that object, as a single block is when parsing
+// native syntax.
+func jsonBodyConfig(body hcl.Body, ctx *hcl.EvalContext, moduleAddress string) map[string]interface{} {
+	config := make(map[string]interface{})
+
+	attrs, _ := body.JustAttributes()
+	for name, attr := range attrs {
+		if name == "dynamic" {
+			continue
+		}
+		if val, ok := jsonValue(attr.Expr, ctx, moduleAddress); ok {
+			config[name] = singleBlock(val)
+		}
+	}
+
+	// Render dynamic blocks as the blocks they generate when possible
+	if attr, ok := attrs["dynamic"]; ok {
+		expandJSONDynamicBlocks(config, attr.Expr, ctx, moduleAddress)
+	}
+
+	return config
+}
+
+// jsonValue evaluates a JSON value. Objects and arrays are walked element by
+// element so that one unresolvable reference doesn't lose the whole value;
+// strings that can't be evaluated fall back to their reference text, as
+// attributeValue does for native syntax.
+func jsonValue(expr hcl.Expression, ctx *hcl.EvalContext, moduleAddress string) (interface{}, bool) {
+	if pairs, diags := hcl.ExprMap(expr); !diags.HasErrors() {
+		result := make(map[string]interface{}, len(pairs))
+		for _, pair := range pairs {
+			key, diags := pair.Key.Value(nil)
+			if diags.HasErrors() || key.Type() != cty.String || key.AsString() == jsonCommentKey {
+				continue
+			}
+			if val, ok := jsonValue(pair.Value, ctx, moduleAddress); ok {
+				result[key.AsString()] = singleBlock(val)
+			}
+		}
+		return result, true
+	}
+
+	if items, diags := hcl.ExprList(expr); !diags.HasErrors() {
+		result := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if val, ok := jsonValue(item, ctx, moduleAddress); ok {
+				result = append(result, val)
+			}
+		}
+		return result, true
+	}
+
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		if ref := extractReference(expr, ctx); ref != "" {
+			return qualifyReference(ref, moduleAddress), true
+		}
+		return nil, false
+	}
+
+	return ctyToGo(val), true
+}
+
+// expandJSONDynamicBlocks renders {"dynamic": {"ingress": {...}}} into the
+// blocks it generates. Dynamic blocks whose for_each can't be evaluated are
+// kept as written.
+func expandJSONDynamicBlocks(config map[string]interface{}, expr hcl.Expression, ctx *hcl.EvalContext, moduleAddress string) {
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return
+	}
+
+	unrendered := make(map[string]interface{})
+	for _, pair := range pairs {
+		label, diags := pair.Key.Value(nil)
+		if diags.HasErrors() || label.Type() != cty.String || label.AsString() == jsonCommentKey {
+			continue
+		}
+
+		if rendered, ok := expandJSONDynamicBlock(label.AsString(), pair.Value, ctx, moduleAddress); ok {
+			for _, blockConfig := range rendered {
+				addBlock(config, label.AsString(), blockConfig)
+			}
+			continue
+		}
+
+		if val, ok := jsonValue(pair.Value, ctx, moduleAddress); ok {
+			unrendered[label.AsString()] = singleBlock(val)
+		}
+	}
+
+	if len(unrendered) > 0 {
+		config["dynamic"] = unrendered
+	}
+}
+
+// expandJSONDynamicBlock renders one dynamic block's content per element of
+// its for_each, like expandDynamicBlock does for native syntax
+func expandJSONDynamicBlock(label string, expr hcl.Expression, ctx *hcl.EvalContext, moduleAddress string) ([]map[string]interface{}, bool) {
+	if items, diags := hcl.ExprList(expr); !diags.HasErrors() {
+		if len(items) != 1 {
+			return nil, false
+		}
+		expr = items[0]
+	}
+
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	props := make(map[string]hcl.Expression, len(pairs))
+	for _, pair := range pairs {
+		if key, diags := pair.Key.Value(nil); !diags.HasErrors() && key.Type() == cty.String {
+			props[key.AsString()] = pair.Value
+		}
+	}
+
+	forEach, ok := props["for_each"]
+	if !ok {
+		return nil, false
+	}
+	content, ok := props["content"]
+	if !ok {
+		return nil, false
+	}
+
+	iterator := label
+	if iterExpr, ok := props["iterator"]; ok {
+		if val, diags := iterExpr.Value(nil); !diags.HasErrors() && val.Type() == cty.String {
+			iterator = val.AsString()
+		}
+	}
+
+	contexts, ok := dynamicContexts(forEach, iterator, ctx)
+	if !ok {
+		return nil, false
+	}
+
+	rendered := make([]map[string]interface{}, 0, len(contexts))
+	for _, child := range contexts {
+		val, _ := jsonValue(content, child, moduleAddress)
+		blockConfig, ok := singleBlock(val).(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		rendered = append(rendered, blockConfig)
+	}
+
+	return rendered, true
+}
+
+// singleBlock unwraps an array holding exactly one object
+func singleBlock(val interface{}) interface{} {
+	if items, ok := val.([]interface{}); ok && len(items) == 1 {
+		if obj, ok := items[0].(map[string]interface{}); ok {
+			return obj
+		}
+	}
+	return val
+}
+
+// interpolatedExpression parses a JSON string that consists of a single
+// interpolation, such as "${aws_s3_bucket.logs.id}", into the expression
+// it wraps. It returns nil for anything else.
+func interpolatedExpression(expr hcl.Expression) hcl.Expression {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+		return nil
+	}
+
+	s := val.AsString()
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") || strings.Count(s, "${") != 1 {
+		return nil
+	}
+
+	inner, diags := hclsyntax.ParseExpression([]byte(s[2:len(s)-1]), expr.Range().Filename, expr.Range().Start)
+	if diags.HasErrors() {
+		return nil
+	}
+	return inner
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// severities are the valid severities of a check, most severe first
+var severities = []string{"critical", "high", "medium", "low"}
+
+// ValidSeverity reports whether severity is critical, high, medium or low
+func ValidSeverity(severity string) bool {
+	return containsString(severities, severity)
+}
+
+// SeverityAtLeast reports whether severity is threshold or more severe.
+// Unknown severities are below every threshold.
+func SeverityAtLeast(severity, threshold string) bool {
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+		if s == threshold {
+			return false
+		}
+	}
+	return false
+}
+
+// checkMetadata builds the crosswalk from the METADATA annotations of the
+// compiled policies. A check is described once, above one of its rules:
+//
+//	# METADATA
+//	# title: S3 bucket encryption at rest
+//	# description: S3 buckets must be encrypted at rest
+//	# related_resources:
+//	#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/serv-side-encryption.html
+//	# custom:
+//	#   check_id: KILN-S3-001
+//	#   severity: critical
+//	#   remediation: Add server_side_encryption_configuration block
+//	#   resource_types: [aws_s3_bucket]
+//	#   controls:
+//	#     soc2: [CC6.6]
+//	#     pci: [3.5.1]
+//
+// Annotations without a custom check_id are ignored.
+func checkMetadata(annotations *ast.AnnotationSet) (Crosswalk, error) {
+	crosswalk := make(Crosswalk)
+	located := make(map[string]*ast.Location)
+
+	for _, ref := range annotations.Flatten() {
+		a := ref.Annotations
+		id, ok := a.Custom["check_id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+		if previous, ok := located[id]; ok {
+			return nil, fmt.Errorf("%s: check %s is already described at %s", a.Location, id, previous)
+		}
+		located[id] = a.Location
+
+		entry, err := parseCheckAnnotations(a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: check %s: %w", a.Location, id, err)
+		}
+		crosswalk[id] = entry
+	}
+	return crosswalk, nil
+}
+
+// parseCheckAnnotations reads the metadata of one check
+func parseCheckAnnotations(a *ast.Annotations) (CrosswalkEntry, error) {
+	entry := CrosswalkEntry{
+		Title:       a.Title,
+		Description: a.Description,
+	}
+	for _, resource := range a.RelatedResources {
+		entry.References = append(entry.References, resource.Ref.String())
+	}
+
+	var err error
+	for key, value := range a.Custom {
+		switch key {
+		case "check_id":
+		case "severity":
+			entry.Severity, err = annotationString(key, value)
+			if err == nil && !containsString(severities, entry.Severity) {
+				err = fmt.Errorf("invalid severity %q: expected critical, high, medium or low", entry.Severity)
+			}
+		case "remediation":
+			entry.Remediation, err = annotationString(key, value)
+		case "control":
+			entry.Control, err = annotationString(key, value)
+		case "resource_types":
+			entry.ResourceTypes, err = annotationStrings(key, value)
+		case "controls":
+			entry.Controls, err = annotationControls(value)
+		}
+		if err != nil {
+			return CrosswalkEntry{}, err
+		}
+	}
+	return entry, nil
+}
+
+// annotationControls reads a map of framework IDs to one or more control IDs
+func annotationControls(value interface{}) (map[string][]string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("controls must map framework IDs to control IDs")
+	}
+
+	controls := make(map[string][]string, len(m))
+	for framework, ids := range m {
+		if _, err := LookupFramework(framework); err != nil {
+			return nil, err
+		}
+		list, err := annotationStrings("controls."+framework, ids)
+		if err != nil {
+			return nil, err
+		}
+		controls[framework] = list
+	}
+	return controls, nil
+}
+
+// annotationString reads a string value
+func annotationString(key string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %v (quote it)", key, value)
+	}
+	return s, nil
+}
+
+// annotationStrings reads a string or a list of strings. Numbers are
+// rejected rather than formatted, since YAML reads a control such as 3.10 as
+// the number 3.1.
+func annotationStrings(key string, value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string or a list of strings", key)
+	}
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		s, err := annotationString(key, item)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
@@ -0,0 +1,486 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth limits how deeply module calls nest. Modules calling
+// themselves are caught as cycles first; this is only a backstop.
+const maxModuleDepth = 16
+
+// moduleMetaArguments are module block arguments that are not input variables
+var moduleMetaArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"count":      true,
+	"for_each":   true,
+	"providers":  true,
+	"depends_on": true,
+}
+
+// moduleContext describes where a module sits in the module tree
+type moduleContext struct {
+	dir     string               // directory containing the module's files
+	rootDir string               // directory of the root module
+	address string               // e.g. "module.network.module.subnets", empty for root
+	key     string               // modules.json key, e.g. "network.subnets"
+	inputs  map[string]cty.Value // module block arguments, nil for the root module
+	depth   int
+	chain   []string // cleaned directories of this module and the modules calling it
+}
+
+// rootModule returns the context of a root module in dir
+func rootModule(dir string) moduleContext {
+	return moduleContext{dir: dir, rootDir: dir, chain: []string{cleanDir(dir)}}
+}
+
+// calls reports whether dir is the directory of this module or of one of
+// the modules calling it
+func (m moduleContext) calls(dir string) bool {
+	dir = cleanDir(dir)
+	for _, ancestor := range m.chain {
+		if ancestor == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleManifest is .terraform/modules/modules.json as written by terraform init
+type moduleManifest struct {
+	Modules []struct {
+		Key    string `json:"Key"`
+		Source string `json:"Source"`
+		Dir    string `json:"Dir"`
+	} `json:"Modules"`
+}
+
+// moduleLoader parses modules and the modules they call
+type moduleLoader struct {
+	parser    *hclparse.Parser
+	opts      ParseOptions
+	manifests map[string]map[string]string // root dir -> module key -> dir
+	errors    []ScanError                  // files and blocks that couldn't be parsed
+
+	suppressions []Suppression // kiln:ignore comments of the parsed files
+
+	// parsed caches each file with its diagnostics. The parser returns a
+	// file it has seen before without them, which would let a file with
+	// syntax errors in half-parsed when its module is called again.
+	parsed map[string]parsedFile
+
+	rootVariables map[string]bool // variables declared by the root modules parsed
+}
+
+func newModuleLoader(opts ParseOptions) *moduleLoader {
+	return &moduleLoader{
+		parser:    hclparse.NewParser(),
+		opts:      opts,
+		manifests: make(map[string]map[string]string),
+		parsed:    make(map[string]parsedFile),
+	}
+}
+
+// parsedFile is a parsed configuration file and the problems found parsing it
+type parsedFile struct {
+	file  *hcl.File
+	diags hcl.Diagnostics
+}
+
+// addError records a problem, once even when a module is called repeatedly
+func (l *moduleLoader) addError(scanErr ScanError) {
+	for _, existing := range l.errors {
+		if existing == scanErr {
+			return
+		}
+	}
+	l.errors = append(l.errors, scanErr)
+}
+
+// undeclaredVars returns the names of the variable values given for
+// variables no root module declares, sorted; none when no root module was
+// parsed
+func (l *moduleLoader) undeclaredVars() []string {
+	if l.rootVariables == nil {
+		return nil
+	}
+	var names []string
+	for name := range l.opts.Vars {
+		if !l.rootVariables[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addDiagnostics records the errors among diags
+func (l *moduleLoader) addDiagnostics(diags hcl.Diagnostics) {
+	for _, scanErr := range diagnosticErrors(diags) {
+		l.addError(scanErr)
+	}
+}
+
+// parseModule extracts resources from the files of one module, evaluating
+// expressions against its variables, tfvars and locals, and descends into
+// the modules it calls
+func (l *moduleLoader) parseModule(files []*hcl.File, mod moduleContext) (*TerraformData, error) {
+	var blocks hcl.Blocks
+	for _, file := range files {
+		// Malformed blocks are recorded and left out; the rest still count
+		bodyContent, _, diags := file.Body.PartialContent(moduleSchema)
+		if diags.HasErrors() {
+			l.addDiagnostics(diags)
+		}
+		if bodyContent != nil {
+			blocks = append(blocks, bodyContent.Blocks...)
+		}
+	}
+
+	if mod.inputs == nil {
+		if l.rootVariables == nil {
+			l.rootVariables = make(map[string]bool)
+		}
+		for _, block := range blocks.OfType("variable") {
+			if len(block.Labels) > 0 {
+				l.rootVariables[block.Labels[0]] = true
+			}
+		}
+	}
+
+	ctx, err := buildEvalContext(blocks.OfType("variable"), blocks.OfType("locals"), mod, l.opts)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate variables: %w", err)
+	}
+
+	data := newTerraformData()
+
+	// Parse resources
+	for _, block := range blocks.OfType("resource") {
+		if len(block.Labels) != 2 {
+			continue
+		}
+		resources, diags := parseResource(block, ctx, mod.address)
+		l.addDiagnostics(diags)
+		data.Resources = append(data.Resources, resources...)
+	}
+
+	// Parse data sources
+	for _, block := range blocks.OfType("data") {
+		if len(block.Labels) != 2 {
+			continue
+		}
+		dataSources, diags := parseDataSource(block, ctx, mod.address)
+		l.addDiagnostics(diags)
+		data.DataSources = append(data.DataSources, dataSources...)
+	}
+
+	// Parse provider configurations
+	for _, block := range blocks.OfType("provider") {
+		data.Providers = append(data.Providers, parseProvider(block, ctx, mod.address))
+	}
+
+	// Settings, variables, locals and outputs are only reported for the root module
+	if mod.inputs == nil {
+		for _, block := range blocks.OfType("terraform") {
+			parseTerraformSettings(block, ctx, data)
+		}
+
+		for _, block := range blocks.OfType("variable") {
+			if len(block.Labels) > 0 {
+				data.Variables[block.Labels[0]] = parseVariable(block)
+			}
+		}
+
+		sources := l.parser.Files()
+		for _, block := range blocks.OfType("output") {
+			if len(block.Labels) == 0 {
+				continue
+			}
+			var src []byte
+			if file, ok := sources[block.DefRange.Filename]; ok {
+				src = file.Bytes
+			}
+			data.Outputs[block.Labels[0]] = parseOutput(block, ctx, src)
+		}
+
+		if locals, ok := ctyToGo(ctx.Variables["local"]).(map[string]interface{}); ok {
+			data.Locals = locals
+		}
+	}
+
+	// Descend into called modules; a module that fails is recorded and skipped
+	for _, block := range blocks.OfType("module") {
+		childData, err := l.parseModuleCall(block, ctx, mod)
+		if err != nil {
+			l.addError(ScanError{
+				File:    block.DefRange.Filename,
+				Line:    block.DefRange.Start.Line,
+				Column:  block.DefRange.Start.Column,
+				Message: err.Error(),
+			})
+			continue
+		}
+		data.Merge(childData)
+	}
+
+	return data, nil
+}
+
+// parseModuleCall resolves the source of a module block and parses it with
+// the block's arguments as input variables, once per instance when the call
+// uses count or for_each. Modules whose source cannot be found locally (e.g.
+// registry modules before terraform init) are skipped.
+func (l *moduleLoader) parseModuleCall(block *hcl.Block, ctx *hcl.EvalContext, parent moduleContext) (*TerraformData, error) {
+	name := block.Labels[0]
+	if parent.depth >= maxModuleDepth {
+		return nil, fmt.Errorf("module %s: nested too deeply", name)
+	}
+
+	attrs, _ := block.Body.JustAttributes()
+
+	source := ""
+	if attr, ok := attrs["source"]; ok {
+		if val, diags := attr.Expr.Value(nil); !diags.HasErrors() && val.Type() == cty.String {
+			source = val.AsString()
+		}
+	}
+
+	key := joinKey(parent.key, name)
+	dir := l.resolveModuleDir(source, parent.dir, parent.rootDir, key)
+	if dir == "" {
+		return newTerraformData(), nil
+	}
+	if parent.calls(dir) {
+		return nil, fmt.Errorf("module %s: module cycle, %s is already a calling module", joinAddress(parent.address, "module."+name), dir)
+	}
+
+	chain := make([]string, len(parent.chain), len(parent.chain)+1)
+	copy(chain, parent.chain)
+	chain = append(chain, cleanDir(dir))
+
+	files, err := l.loadModuleFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("module %s: %w", joinAddress(parent.address, "module."+name), err)
+	}
+
+	instances, diags := expandInstances(block.Body, ctx)
+	l.addDiagnostics(diags)
+
+	data := newTerraformData()
+	for _, inst := range instances {
+		child := moduleContext{
+			dir:     dir,
+			rootDir: parent.rootDir,
+			address: joinAddress(parent.address, "module."+name+inst.addressKey()),
+			key:     key,
+			inputs:  make(map[string]cty.Value),
+			depth:   parent.depth + 1,
+			chain:   chain,
+		}
+
+		for argName, attr := range attrs {
+			if moduleMetaArguments[argName] {
+				continue
+			}
+			child.inputs[argName] = moduleInputValue(attr, inst.ctx, parent.address)
+		}
+
+		childData, err := l.parseModule(files, child)
+		if err != nil {
+			return nil, err
+		}
+		data.Merge(childData)
+	}
+
+	return data, nil
+}
+
+// moduleInputValue evaluates a module argument. References to resources in
+// the calling module can't be evaluated, so they are passed through as their
+// reference text, the same way resource attributes are.
+func moduleInputValue(attr *hcl.Attribute, ctx *hcl.EvalContext, moduleAddress string) cty.Value {
+	val, diags := attr.Expr.Value(ctx)
+	if !diags.HasErrors() && val.IsWhollyKnown() {
+		return val
+	}
+
+	if ref := extractReference(attr.Expr, ctx); ref != "" {
+		return cty.StringVal(qualifyReference(ref, moduleAddress))
+	}
+
+	return cty.DynamicVal
+}
+
+// resolveModuleDir finds the directory holding a module's source. Local
+// paths are resolved relative to the calling module; anything else is looked
+// up in the modules.json manifest that terraform init writes.
+func (l *moduleLoader) resolveModuleDir(source, parentDir, rootDir, key string) string {
+	if isLocalSource(source) {
+		dir := filepath.Join(parentDir, source)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		return ""
+	}
+
+	if rootDir == "" {
+		return ""
+	}
+
+	manifest, ok := l.manifests[rootDir]
+	if !ok {
+		manifest = loadModuleManifest(rootDir)
+		l.manifests[rootDir] = manifest
+	}
+
+	return manifest[key]
+}
+
+// parseFile parses a configuration file in native or JSON syntax,
+// depending on its name, once: later calls return the same file and
+// diagnostics. The kiln:ignore comments of native syntax files are recorded
+// as they are parsed.
+func (l *moduleLoader) parseFile(content []byte, path string) (*hcl.File, hcl.Diagnostics) {
+	if cached, ok := l.parsed[path]; ok {
+		return cached.file, cached.diags
+	}
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if isJSONFile(path) {
+		file, diags = l.parser.ParseJSON(content, path)
+	} else {
+		file, diags = l.parser.ParseHCL(content, path)
+	}
+	l.parsed[path] = parsedFile{file: file, diags: diags}
+
+	if !isJSONFile(path) && !diags.HasErrors() {
+		suppressions, errs := parseSuppressions(content, path)
+		l.suppressions = append(l.suppressions, suppressions...)
+		for _, scanErr := range errs {
+			l.addError(scanErr)
+		}
+	}
+	return file, diags
+}
+
+// loadModuleFiles parses the .tf and .tf.json files directly inside dir.
+// Files that can't be parsed are recorded and left out.
+func (l *moduleLoader) loadModuleFiles(dir string) ([]*hcl.File, error) {
+	var paths []string
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var files []*hcl.File
+	for _, path := range paths {
+		var content []byte
+		if _, ok := l.parsed[path]; !ok {
+			var err error
+			if content, err = os.ReadFile(path); err != nil {
+				l.addError(ScanError{File: path, Message: err.Error()})
+				continue
+			}
+		}
+
+		file, diags := l.parseFile(content, path)
+		if diags.HasErrors() {
+			l.addDiagnostics(diags)
+			continue
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// loadModuleManifest reads .terraform/modules/modules.json under rootDir,
+// returning module directories keyed by module key
+func loadModuleManifest(rootDir string) map[string]string {
+	dirs := make(map[string]string)
+
+	content, err := os.ReadFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return dirs
+	}
+
+	var manifest moduleManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return dirs
+	}
+
+	for _, m := range manifest.Modules {
+		if m.Key == "" || m.Dir == "" {
+			continue
+		}
+		dirs[m.Key] = filepath.Join(rootDir, m.Dir)
+	}
+
+	return dirs
+}
+
+// localModuleDirs returns the directories of local modules called from files
+func localModuleDirs(files []*hcl.File, dir string) []string {
+	var dirs []string
+	for _, file := range files {
+		bodyContent, _, _ := file.Body.PartialContent(moduleSchema)
+		for _, block := range bodyContent.Blocks.OfType("module") {
+			attrs, _ := block.Body.JustAttributes()
+			attr, ok := attrs["source"]
+			if !ok {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || val.Type() != cty.String || !isLocalSource(val.AsString()) {
+				continue
+			}
+			dirs = append(dirs, cleanDir(filepath.Join(dir, val.AsString())))
+		}
+	}
+	return dirs
+}
+
+// isLocalSource reports whether a module source is a local path
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// cleanDir normalizes a directory so that different spellings compare equal
+func cleanDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return filepath.Clean(dir)
+}
+
+func joinAddress(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
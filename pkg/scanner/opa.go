@@ -6,116 +6,446 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
 )
 
+// DefaultEntrypoint is the rule evaluated in every policy package when no
+// entrypoints are configured
+const DefaultEntrypoint = "evaluate"
+
+// checksPackage is the Rego package of the bundled checks
+const checksPackage = "checks"
+
 // OPAEvaluator evaluates OPA policies
 type OPAEvaluator struct {
 	policyPaths []string
-	query       rego.PreparedEvalQuery
+	queries     []policyQuery
+	crosswalk   Crosswalk
+	frameworks  []Framework // reported against, in the order selected
+
+	enabled, disabled []string          // check ID patterns
+	severities        map[string]string // severity overrides by check ID
+	settings          map[string]interface{}
+}
+
+// policyQuery is a prepared entrypoint and the package it belongs to
+type policyQuery struct {
+	pkg   string
+	query rego.PreparedEvalQuery
 }
 
 // NewOPAEvaluator creates a new OPA evaluator
 func NewOPAEvaluator(policyPaths []string) (*OPAEvaluator, error) {
-	ctx := context.Background()
+	return NewOPAEvaluatorContext(context.Background(), policyPaths)
+}
+
+// NewOPAEvaluatorContext is NewOPAEvaluator with a context for cancelling
+// policy compilation
+func NewOPAEvaluatorContext(ctx context.Context, policyPaths []string) (*OPAEvaluator, error) {
+	return NewOPAEvaluatorWithConfig(ctx, PolicyConfig{Paths: policyPaths})
+}
+
+// NewOPAEvaluatorWithConfig creates an evaluator from a policy bundle and
+// policies on disk, as selected by cfg
+func NewOPAEvaluatorWithConfig(ctx context.Context, cfg PolicyConfig) (*OPAEvaluator, error) {
+	policies, err := loadPolicies(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := cfg.Frameworks
+	if len(ids) == 0 {
+		ids = []string{DefaultFramework}
+	}
+	for id, severity := range cfg.Severities {
+		if !containsString(severities, severity) {
+			return nil, fmt.Errorf("check %s: invalid severity %q: expected critical, high, medium or low", id, severity)
+		}
+	}
 
-	// Create Rego query
-	r := rego.New(
-		rego.Query("data.soc2.evaluate"),
-		rego.Load(policyPaths, nil),
-	)
+	evaluator := &OPAEvaluator{
+		policyPaths: cfg.Paths,
+		enabled:     cfg.EnabledChecks,
+		disabled:    cfg.DisabledChecks,
+		severities:  cfg.Severities,
+		settings:    cfg.Settings,
+	}
+	for _, id := range ids {
+		framework, err := LookupFramework(id)
+		if err != nil {
+			return nil, err
+		}
+		evaluator.frameworks = append(evaluator.frameworks, framework)
+	}
 
-	// Prepare query (compile policies)
-	query, err := r.PrepareForEval(ctx)
+	// Compile once, then prepare a query per entrypoint
+	compiler, err := policies.compile()
 	if err != nil {
-		return nil, fmt.Errorf("prepare OPA query: %w", err)
+		return nil, fmt.Errorf("compile policies: %w", err)
+	}
+	evaluator.crosswalk = policies.crosswalk
+
+	entrypoints := cfg.Entrypoints
+	if len(entrypoints) == 0 {
+		entrypoints = defaultEntrypoints(compiler)
+		if len(entrypoints) == 0 {
+			return nil, fmt.Errorf("no policy package defines an %s rule", DefaultEntrypoint)
+		}
+	}
+
+	for _, entrypoint := range entrypoints {
+		ref, err := ast.ParseRef(entrypoint)
+		if err != nil || !ref.HasPrefix(ast.DefaultRootRef) || len(ref) < 3 {
+			return nil, fmt.Errorf("invalid entrypoint %q: expected data.<package>.<rule>", entrypoint)
+		}
+		if len(compiler.GetRules(ref)) == 0 {
+			return nil, fmt.Errorf("entrypoint %s is not defined by any policy", entrypoint)
+		}
+
+		query, err := rego.New(
+			rego.Compiler(compiler),
+			rego.Query(entrypoint),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("prepare OPA query %s: %w", entrypoint, err)
+		}
+
+		evaluator.queries = append(evaluator.queries, policyQuery{
+			pkg:   packageName(ref),
+			query: query,
+		})
 	}
 
-	return &OPAEvaluator{
-		policyPaths: policyPaths,
-		query:       query,
-	}, nil
+	return evaluator, nil
+}
+
+// defaultEntrypoints returns data.<package>.evaluate for every package that
+// defines an evaluate rule
+func defaultEntrypoints(compiler *ast.Compiler) []string {
+	seen := make(map[string]bool)
+	var entrypoints []string
+	for _, module := range compiler.Modules {
+		for _, rule := range module.Rules {
+			if rule.Head.Name.String() != DefaultEntrypoint {
+				continue
+			}
+			entrypoint := module.Package.Path.Append(ast.StringTerm(DefaultEntrypoint)).String()
+			if !seen[entrypoint] {
+				seen[entrypoint] = true
+				entrypoints = append(entrypoints, entrypoint)
+			}
+		}
+	}
+	// Bundled checks first, then custom packages
+	sort.Slice(entrypoints, func(i, j int) bool {
+		bi := packageName(ast.MustParseRef(entrypoints[i])) == checksPackage
+		bj := packageName(ast.MustParseRef(entrypoints[j])) == checksPackage
+		if bi != bj {
+			return bi
+		}
+		return entrypoints[i] < entrypoints[j]
+	})
+	return entrypoints
+}
+
+// packageName returns the package of an entrypoint without the data prefix,
+// e.g. acme.rules for data.acme.rules.evaluate
+func packageName(entrypoint ast.Ref) string {
+	parts := make([]string, 0, len(entrypoint)-2)
+	for _, term := range entrypoint[1 : len(entrypoint)-1] {
+		if s, ok := term.Value.(ast.String); ok {
+			parts = append(parts, string(s))
+		} else {
+			parts = append(parts, term.String())
+		}
+	}
+	return strings.Join(parts, ".")
 }
 
 // Evaluate runs OPA policies against Terraform data
 func (e *OPAEvaluator) Evaluate(data *TerraformData) (*Result, error) {
-	ctx := context.Background()
+	return e.EvaluateContext(context.Background(), data)
+}
 
+// EvaluateContext is Evaluate with a context for cancellation. Findings of
+// all entrypoints are merged into one Result.
+func (e *OPAEvaluator) EvaluateContext(ctx context.Context, data *TerraformData) (*Result, error) {
 	// Prepare input for OPA
 	input := map[string]interface{}{
-		"resources": data.Resources,
-		"variables": data.Variables,
-		"outputs":   data.Outputs,
+		"resources":          data.Resources,
+		"data_sources":       data.DataSources,
+		"providers":          data.Providers,
+		"backends":           data.Backends,
+		"required_providers": data.RequiredProviders,
+		"variables":          data.Variables,
+		"locals":             data.Locals,
+		"outputs":            data.Outputs,
+		"config":             e.settings,
 	}
 
-	// Evaluate
-	results, err := e.query.Eval(ctx, rego.EvalInput(input))
-	if err != nil {
-		return nil, fmt.Errorf("evaluate policies: %w", err)
-	}
-
-	// Parse OPA results
-	return parseOPAResults(results), nil
-}
-
-// parseOPAResults converts OPA output to Result
-func parseOPAResults(results rego.ResultSet) *Result {
 	result := &Result{
 		Violations: []Finding{},
 		Warnings:   []Finding{},
 		Passed:     []Finding{},
+		Suppressed: []Finding{},
+		Errors:     []ScanError{},
 		ScannedAt:  time.Now().Format(time.RFC3339),
 	}
 
-	if len(results) == 0 {
-		return result
+	for _, q := range e.queries {
+		// Evaluate
+		results, err := q.query.Eval(ctx, rego.EvalInput(input))
+		if err != nil {
+			return nil, fmt.Errorf("evaluate policies in %s: %w", q.pkg, err)
+		}
+
+		// Parse OPA results
+		parseOPAResults(results, q.pkg, result)
 	}
 
-	// Extract findings from OPA result
-	// OPA returns: {violations: [...], warnings: [...], passed: [...]}
-	if len(results) > 0 && len(results[0].Expressions) > 0 {
-		data, ok := results[0].Expressions[0].Value.(map[string]interface{})
-		if !ok {
-			return result
+	// Keep the selected checks
+	result.Violations = e.selectChecks(result.Violations)
+	result.Warnings = e.selectChecks(result.Warnings)
+	result.Passed = e.selectChecks(result.Passed)
+
+	// Describe findings from their checks' metadata
+	e.describe(result.Violations, true)
+	e.describe(result.Warnings, true)
+	e.describe(result.Passed, false)
+
+	// Report checks against the selected frameworks' controls
+	result.Violations = e.mapControls(result.Violations)
+	result.Warnings = e.mapControls(result.Warnings)
+	result.Passed = e.mapControls(result.Passed)
+
+	// Set aside the findings accepted by kiln:ignore comments, which are
+	// matched by location
+	attachLocations(result, data)
+	applySuppressions(result, data.Suppressions, time.Now())
+
+	// Calculate score
+	result.Score = scorePercent(len(result.Violations), len(result.Warnings), len(result.Passed))
+	result.Frameworks = e.frameworkScores(result)
+
+	attachFingerprints(result)
+
+	return result, nil
+}
+
+// MapFindings reports findings produced outside the policies, such as those
+// of CompareState, like EvaluateContext reports its own: it keeps the
+// selected checks, describes them from the crosswalk, maps them to the
+// selected frameworks' controls and scores result again
+func (e *OPAEvaluator) MapFindings(result *Result) {
+	for _, set := range []struct {
+		findings *[]Finding
+		failing  bool
+	}{
+		{&result.Violations, true},
+		{&result.Warnings, true},
+		{&result.Passed, false},
+		{&result.Suppressed, true},
+	} {
+		*set.findings = e.selectChecks(*set.findings)
+		e.describe(*set.findings, set.failing)
+		*set.findings = e.mapControls(*set.findings)
+	}
+
+	result.Score = scorePercent(len(result.Violations), len(result.Warnings), len(result.Passed))
+	result.Frameworks = e.frameworkScores(result)
+}
+
+// describe fills in what findings leave out from the metadata of their
+// checks, and applies severity overrides. Severity and remediation only
+// apply to failing findings.
+func (e *OPAEvaluator) describe(findings []Finding, failing bool) {
+	for i := range findings {
+		f := &findings[i]
+		if severity, ok := e.severities[f.CheckID]; ok && failing && f.CheckID != "" {
+			f.Severity = severity
+		}
+
+		entry, ok := e.crosswalk[f.CheckID]
+		if !ok || f.CheckID == "" {
+			continue
+		}
+
+		if f.Title == "" {
+			f.Title = entry.Title
+		}
+		if len(f.References) == 0 {
+			f.References = entry.References
+		}
+		if f.Control == "" {
+			f.Control = entry.Control
 		}
+		if failing && f.Severity == "" {
+			f.Severity = entry.Severity
+		}
+		if failing && f.Remediation == "" {
+			f.Remediation = entry.Remediation
+		}
+	}
+}
+
+// selectChecks drops the findings of checks that aren't enabled or are
+// disabled. Findings without a check ID are kept.
+func (e *OPAEvaluator) selectChecks(findings []Finding) []Finding {
+	if len(e.enabled) == 0 && len(e.disabled) == 0 {
+		return findings
+	}
+
+	selected := findings[:0]
+	for _, f := range findings {
+		if f.CheckID == "" ||
+			((len(e.enabled) == 0 || matchesAny(e.enabled, f.CheckID)) && !matchesAny(e.disabled, f.CheckID)) {
+			selected = append(selected, f)
+		}
+	}
+	return selected
+}
+
+// matchesAny reports whether s matches any of the glob patterns
+func matchesAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if globMatch(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
 
-		// Parse violations
-		if violations, ok := data["violations"].([]interface{}); ok {
-			for _, v := range violations {
-				if finding := parseFinding(v); finding != nil {
-					result.Violations = append(result.Violations, *finding)
-				}
+// mapControls attaches the crosswalk controls of each finding's check and
+// reports it against the first selected framework that maps the check.
+// Findings of checks mapped only to other frameworks are dropped; findings
+// of checks mapped to no framework, such as those of custom policies, are
+// kept as is.
+func (e *OPAEvaluator) mapControls(findings []Finding) []Finding {
+	mapped := findings[:0]
+	for _, f := range findings {
+		if len(e.crosswalk[f.CheckID].Controls) == 0 || f.CheckID == "" {
+			mapped = append(mapped, f)
+			continue
+		}
+
+		f.Controls = e.crosswalk.Controls(f.CheckID)
+		for _, framework := range e.frameworks {
+			if controls := f.ControlsIn(framework.ID); len(controls) > 0 {
+				f.Control = strings.Join(controls, ", ")
+				f.Framework = framework.ID
+				mapped = append(mapped, f)
+				break
 			}
 		}
+	}
+	return mapped
+}
 
-		// Parse warnings
-		if warnings, ok := data["warnings"].([]interface{}); ok {
-			for _, w := range warnings {
-				if finding := parseFinding(w); finding != nil {
-					result.Warnings = append(result.Warnings, *finding)
-				}
+// frameworkScores scores each selected framework on the findings mapped to
+// it, and each custom policy package on its own findings
+func (e *OPAEvaluator) frameworkScores(result *Result) []FrameworkScore {
+	var scores []FrameworkScore
+	for _, f := range e.frameworks {
+		score := FrameworkScore{Framework: f.ID, Name: f.Name}
+		score.tally(result, func(finding Finding) bool {
+			return len(finding.ControlsIn(f.ID)) > 0
+		})
+		scores = append(scores, score)
+	}
+
+	// Custom policy packages, whose findings have no crosswalk controls
+	seen := make(map[string]bool)
+	for _, findings := range [][]Finding{result.Violations, result.Warnings, result.Passed} {
+		for _, finding := range findings {
+			if len(finding.Controls) > 0 || seen[finding.Package] {
+				continue
 			}
+			seen[finding.Package] = true
+
+			pkg := finding.Package
+			score := FrameworkScore{Framework: pkg, Name: pkg}
+			score.tally(result, func(finding Finding) bool {
+				return len(finding.Controls) == 0 && finding.Package == pkg
+			})
+			scores = append(scores, score)
 		}
+	}
+	return scores
+}
 
-		// Parse passed
-		if passed, ok := data["passed"].([]interface{}); ok {
-			for _, p := range passed {
-				if finding := parseFinding(p); finding != nil {
-					result.Passed = append(result.Passed, *finding)
-				}
+// tally counts the findings of result that match and scores them
+func (s *FrameworkScore) tally(result *Result, match func(Finding) bool) {
+	for _, set := range []struct {
+		findings []Finding
+		count    *int
+	}{
+		{result.Violations, &s.Violations},
+		{result.Warnings, &s.Warnings},
+		{result.Passed, &s.Passed},
+	} {
+		for _, finding := range set.findings {
+			if match(finding) {
+				*set.count++
 			}
 		}
 	}
+	s.Score = scorePercent(s.Violations, s.Warnings, s.Passed)
+}
 
-	// Calculate score
-	total := len(result.Violations) + len(result.Warnings) + len(result.Passed)
-	if total > 0 {
-		result.Score = (len(result.Passed) * 100) / total
+// attachLocations fills in the source location of each finding from the
+// resource it refers to
+func attachLocations(result *Result, data *TerraformData) {
+	for _, findings := range [][]Finding{result.Violations, result.Warnings, result.Passed, result.Suppressed} {
+		for i := range findings {
+			file, rng := data.Locate(findings[i].Resource)
+
+			findings[i].File = file
+			if rng != nil {
+				findings[i].StartLine = rng.StartLine
+				findings[i].StartColumn = rng.StartColumn
+				findings[i].EndLine = rng.EndLine
+			}
+		}
+	}
+}
+
+// parseOPAResults adds the findings of one entrypoint to result, recording
+// the package that produced them
+func parseOPAResults(results rego.ResultSet, pkg string, result *Result) {
+	// Extract findings from OPA result
+	// OPA returns: {violations: [...], warnings: [...], passed: [...]}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return
+	}
+	data, ok := results[0].Expressions[0].Value.(map[string]interface{})
+	if !ok {
+		return
 	}
 
-	return result
+	for _, set := range []struct {
+		key      string
+		findings *[]Finding
+	}{
+		{"violations", &result.Violations},
+		{"warnings", &result.Warnings},
+		{"passed", &result.Passed},
+	} {
+		items, ok := data[set.key].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			if finding := parseFinding(item); finding != nil {
+				finding.Package = pkg
+				*set.findings = append(*set.findings, *finding)
+			}
+		}
+	}
 }
 
 // parseFinding converts OPA finding to Finding struct
@@ -127,6 +457,9 @@ func parseFinding(data interface{}) *Finding {
 
 	finding := &Finding{}
 
+	if checkID, ok := m["check_id"].(string); ok {
+		finding.CheckID = checkID
+	}
 	if control, ok := m["control"].(string); ok {
 		finding.Control = control
 	}
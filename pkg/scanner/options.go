@@ -0,0 +1,112 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"io/fs"
+
+	"github.com/usekiln/kiln/policies"
+)
+
+// Options configures a Scanner created with NewWithOptions
+type Options struct {
+	// PolicyFS is the policy bundle, with the checks and control catalog in
+	// checks/; nil uses the bundle embedded in the binary
+	PolicyFS fs.FS
+
+	// Frameworks are the IDs of the frameworks to report against, e.g. soc2
+	// or cis-aws; empty selects DefaultFramework
+	Frameworks []string
+
+	// PolicyPaths are Rego files, crosswalks or directories loaded on top of
+	// PolicyFS. A file replaces the bundled policy with the same path
+	// relative to checks/ and the same package; other files are added.
+	PolicyPaths []string
+
+	// Entrypoints are the Rego rules to query, e.g. data.acme.evaluate. When
+	// empty, every package that defines an evaluate rule is queried.
+	Entrypoints []string
+
+	// EnabledChecks, when set, limits findings to the checks whose IDs
+	// match one of these patterns, e.g. KILN-S3-*; DisabledChecks leaves out
+	// the checks matching any of its patterns
+	EnabledChecks  []string
+	DisabledChecks []string
+
+	// Severities overrides the severity of checks by check ID, e.g.
+	// KILN-S3-003: high
+	Severities map[string]string
+
+	// RequiredTags are the tags every taggable resource must carry; empty
+	// requires Environment and Owner
+	RequiredTags []string
+
+	// Exclude are glob patterns of files and directories left out of
+	// directory scans. Patterns without a slash match names at any depth;
+	// others match paths relative to the scanned directory, or absolute
+	// paths when absolute. ** matches any number of directories.
+	Exclude []string
+
+	// Parse controls how Terraform variables are resolved
+	Parse ParseOptions
+
+	// Exceptions are applied to every scan, moving the findings they cover
+	// to Result.Suppressed; see LoadExceptions
+	Exceptions []Exception
+
+	// Baseline, when set, moves the findings it records to
+	// Result.Baselined after exceptions are applied; see LoadBaseline
+	Baseline *Baseline
+
+	// Progress, when set, is called as a scan advances. The scanner never
+	// writes to stdout or stderr itself.
+	Progress ProgressFunc
+}
+
+// policyConfig selects the checks of the bundle and the policies on disk
+func (opts Options) policyConfig() PolicyConfig {
+	policyFS := opts.PolicyFS
+	if policyFS == nil {
+		policyFS = policies.FS
+	}
+	cfg := PolicyConfig{
+		FS:             policyFS,
+		Packs:          []string{checksPackage},
+		Paths:          opts.PolicyPaths,
+		Entrypoints:    opts.Entrypoints,
+		Frameworks:     opts.Frameworks,
+		EnabledChecks:  opts.EnabledChecks,
+		DisabledChecks: opts.DisabledChecks,
+		Severities:     opts.Severities,
+	}
+	if len(opts.RequiredTags) > 0 {
+		cfg.Settings = map[string]interface{}{"required_tags": opts.RequiredTags}
+	}
+	return cfg
+}
+
+// ProgressStage identifies a step of a scan
+type ProgressStage string
+
+const (
+	// ProgressDiscovered is reported once the files to scan are known
+	ProgressDiscovered ProgressStage = "discovered"
+
+	// ProgressParsed is reported once the files are parsed
+	ProgressParsed ProgressStage = "parsed"
+
+	// ProgressEvaluated is reported once the policies have been evaluated
+	ProgressEvaluated ProgressStage = "evaluated"
+)
+
+// Progress describes a step of a scan
+type Progress struct {
+	Stage ProgressStage
+	Path  string // directory, plan or state file being scanned; empty for a list of files
+	Files int    // number of files involved in the step
+}
+
+// ProgressFunc receives progress updates, e.g. to log them or drive a
+// progress indicator
+type ProgressFunc func(Progress)
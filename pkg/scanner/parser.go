@@ -4,227 +4,525 @@
 package scanner
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // ParseTerraform to parse HCL/Terraform content
 func ParseTerraform(content []byte) (*TerraformData, error) {
-	parser := hclparse.NewParser()
+	return ParseTerraformFile(content, "input.tf")
+}
 
-	// Parse HCL
-	file, diag := parser.ParseHCL(content, "input.tf")
-	if diag.HasErrors() {
-		return nil, fmt.Errorf("parse error: %s", diag.Error())
-	}
+// ParseTerraformFiles parses each file independently and merges the results.
+// Files in the same directory form a module and share variables and locals.
+// A file that can't be read or parsed, or a module that can't be evaluated,
+// is recorded in Errors and the rest are still parsed.
+func ParseTerraformFiles(paths []string, opts ParseOptions) (*TerraformData, error) {
+	return parseTerraformFiles(context.Background(), paths, opts)
+}
 
-	// Extract content
-	body := file.Body
-	contentSchema := &hcl.BodySchema{
-		Blocks: []hcl.BlockHeaderSchema{
-			{Type: "resource", LabelNames: []string{"type", "name"}},
-			{Type: "variable", LabelNames: []string{"name"}},
-			{Type: "output", LabelNames: []string{"name"}},
-		},
+// parseTerraformFiles is ParseTerraformFiles, stopping early when ctx is done
+func parseTerraformFiles(ctx context.Context, paths []string, opts ParseOptions) (*TerraformData, error) {
+	return newModuleLoader(opts).parseFiles(ctx, paths)
+}
+
+// UndeclaredVars returns the names of opts.Vars that no root module among
+// the files at paths declares, sorted. Terraform rejects such values, and a
+// mistyped name would otherwise scan with the variable's default unnoticed.
+func UndeclaredVars(paths []string, opts ParseOptions) ([]string, error) {
+	loader := newModuleLoader(opts)
+	if _, err := loader.parseFiles(context.Background(), paths); err != nil {
+		return nil, err
 	}
+	return loader.undeclaredVars(), nil
+}
 
-	bodyContent, _, diag := body.PartialContent(contentSchema)
-	if diag.HasErrors() {
-		return nil, fmt.Errorf("content error: %s", diag.Error())
+// parseFiles parses the files at paths, each directory as a root module
+// unless another one calls it
+func (l *moduleLoader) parseFiles(ctx context.Context, paths []string) (*TerraformData, error) {
+	data := newTerraformData()
+
+	var dirs []string
+	filesByDir := make(map[string][]*hcl.File)
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			l.addError(ScanError{File: path, Message: err.Error()})
+			continue
+		}
+
+		file, diags := l.parseFile(content, path)
+		if diags.HasErrors() {
+			l.addDiagnostics(diags)
+			continue
+		}
+
+		dir := filepath.Dir(path)
+		if _, seen := filesByDir[dir]; !seen {
+			dirs = append(dirs, dir)
+		}
+		filesByDir[dir] = append(filesByDir[dir], file)
 	}
 
-	data := &TerraformData{
-		Resources: []Resource{},
-		Variables: make(map[string]Variable),
-		Outputs:   make(map[string]Output),
+	// Directories called as modules by another scanned directory are
+	// scanned through that call, with its inputs, rather than on their own.
+	// A directory calling itself is still scanned, so the cycle is reported.
+	called := make(map[string]bool)
+	for _, dir := range dirs {
+		for _, child := range localModuleDirs(filesByDir[dir], dir) {
+			if child != cleanDir(dir) {
+				called[child] = true
+			}
+		}
 	}
 
-	// Parse resources
-	for _, block := range bodyContent.Blocks.OfType("resource") {
-		if len(block.Labels) != 2 {
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if called[cleanDir(dir)] {
 			continue
 		}
 
-		resourceType := block.Labels[0]
-		resourceName := block.Labels[1]
+		moduleData, err := l.parseModule(filesByDir[dir], rootModule(dir))
+		if err != nil {
+			l.addError(ScanError{File: dir, Message: err.Error()})
+			continue
+		}
 
-		// Extract resource configuration
-		attrs, _ := block.Body.JustAttributes()
-		config := make(map[string]interface{})
+		data.Merge(moduleData)
+	}
 
-		for name, attr := range attrs {
-			// Try to extract the value
-			val, valDiags := attr.Expr.Value(nil)
+	data.Errors = append(data.Errors, l.errors...)
+	data.Suppressions = append(data.Suppressions, l.suppressions...)
 
-			// If we can't evaluate it (e.g., it's a reference), get the raw expression
-			if valDiags.HasErrors() {
-				// Handle Terraform references like aws_s3_bucket.example.id
-				if refVal := extractReference(attr.Expr); refVal != "" {
-					config[name] = refVal
-				}
-			} else {
-				config[name] = ctyToGo(val)
-			}
+	return data, nil
+}
+
+// ParseTerraformFile parses HCL/Terraform content, recording filename as the
+// source of every resource. Filenames ending in .json are parsed as JSON syntax.
+func ParseTerraformFile(content []byte, filename string) (*TerraformData, error) {
+	loader := newModuleLoader(ParseOptions{})
+
+	file, diag := loader.parseFile(content, filename)
+	if diag.HasErrors() {
+		return nil, fmt.Errorf("parse error: %s", diag.Error())
+	}
+
+	data, err := loader.parseModule([]*hcl.File{file}, rootModule(""))
+	if err != nil {
+		return nil, err
+	}
+	data.Errors = append(data.Errors, loader.errors...)
+	data.Suppressions = append(data.Suppressions, loader.suppressions...)
+
+	return data, nil
+}
+
+// diagnosticErrors converts the errors among diags into ScanErrors
+func diagnosticErrors(diags hcl.Diagnostics) []ScanError {
+	var errs []ScanError
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
 		}
 
-		// Check for nested blocks
-		nestedSchema := &hcl.BodySchema{
-			Blocks: []hcl.BlockHeaderSchema{
-				{Type: "server_side_encryption_configuration"},
-				{Type: "logging"},
-				{Type: "versioning"},
-				{Type: "versioning_configuration"},
-				{Type: "rule"},
-				{Type: "default_action"},
-				{Type: "redirect"},
-			},
+		scanErr := ScanError{Message: diag.Summary}
+		if diag.Detail != "" {
+			scanErr.Message += ": " + diag.Detail
 		}
-		nestedContent, _, _ := block.Body.PartialContent(nestedSchema)
-
-		// Process nested blocks and extract their attributes
-		for _, nestedBlock := range nestedContent.Blocks {
-			// Mark that the block exists
-			config[nestedBlock.Type] = true
-
-			// Extract attributes from nested blocks
-			nestedAttrs, _ := nestedBlock.Body.JustAttributes()
-			nestedConfig := make(map[string]interface{})
-
-			for name, attr := range nestedAttrs {
-				val, valDiags := attr.Expr.Value(nil)
-				if valDiags.HasErrors() {
-					if refVal := extractReference(attr.Expr); refVal != "" {
-						nestedConfig[name] = refVal
-					}
-				} else {
-					nestedConfig[name] = ctyToGo(val)
-				}
-			}
+		if diag.Subject != nil {
+			scanErr.File = diag.Subject.Filename
+			scanErr.Line = diag.Subject.Start.Line
+			scanErr.Column = diag.Subject.Start.Column
+		}
+		errs = append(errs, scanErr)
+	}
+	return errs
+}
 
-			// Store nested block config if it has attributes
-			if len(nestedConfig) > 0 {
-				// Handle as array if multiple blocks of same type
-				if existing, exists := config[nestedBlock.Type]; exists {
-					if existingMap, ok := existing.(map[string]interface{}); ok {
-						// Convert to array
-						config[nestedBlock.Type] = []interface{}{existingMap, nestedConfig}
-					} else if existingArray, ok := existing.([]interface{}); ok {
-						// Append to existing array
-						config[nestedBlock.Type] = append(existingArray, nestedConfig)
-					}
-				} else {
-					config[nestedBlock.Type] = nestedConfig
-				}
-			}
+// moduleSchema lists the top-level blocks the scanner understands
+var moduleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "terraform"},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "locals"},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
 
-			// Recursively handle nested blocks within nested blocks
-			deepNestedSchema := &hcl.BodySchema{
-				Blocks: []hcl.BlockHeaderSchema{
-					{Type: "redirect"},
-					{Type: "rule"},
-					{Type: "apply_server_side_encryption_by_default"},
-				},
-			}
-			deepNestedContent, _, _ := nestedBlock.Body.PartialContent(deepNestedSchema)
-
-			for _, deepBlock := range deepNestedContent.Blocks {
-				deepAttrs, _ := deepBlock.Body.JustAttributes()
-				deepConfig := make(map[string]interface{})
-
-				for name, attr := range deepAttrs {
-					val, valDiags := attr.Expr.Value(nil)
-					if valDiags.HasErrors() {
-						if refVal := extractReference(attr.Expr); refVal != "" {
-							deepConfig[name] = refVal
-						}
-					} else {
-						deepConfig[name] = ctyToGo(val)
-					}
-				}
+// parseResource extracts the configuration of a resource block, one Resource
+// per instance when count or for_each can be evaluated. Resources in child
+// modules are addressed relative to the root, e.g. module.x.aws_s3_bucket.logs
+func parseResource(block *hcl.Block, ctx *hcl.EvalContext, moduleAddress string) ([]Resource, hcl.Diagnostics) {
+	return parseInstances(block, ctx, moduleAddress, "")
+}
 
-				if len(deepConfig) > 0 {
-					if nestedMap, ok := config[nestedBlock.Type].(map[string]interface{}); ok {
-						nestedMap[deepBlock.Type] = deepConfig
-					}
-				}
-			}
+// parseDataSource extracts a data block the same way, addressed as data.<type>.<name>
+func parseDataSource(block *hcl.Block, ctx *hcl.EvalContext, moduleAddress string) ([]Resource, hcl.Diagnostics) {
+	return parseInstances(block, ctx, moduleAddress, "data.")
+}
+
+func parseInstances(block *hcl.Block, ctx *hcl.EvalContext, moduleAddress, addressPrefix string) ([]Resource, hcl.Diagnostics) {
+	resourceType := block.Labels[0]
+	resourceName := block.Labels[1]
+
+	address := fmt.Sprintf("%s%s.%s", addressPrefix, resourceType, resourceName)
+	if moduleAddress != "" {
+		address = moduleAddress + "." + address
+	}
+
+	instances, diags := expandInstances(block.Body, ctx)
+	var resources []Resource
+	for _, inst := range instances {
+		// Extract resource configuration, including nested blocks at any depth
+		config := bodyConfig(block.Body, inst.ctx, moduleAddress)
+		if inst.index() != nil {
+			delete(config, "count")
+			delete(config, "for_each")
 		}
 
-		data.Resources = append(data.Resources, Resource{
+		resources = append(resources, Resource{
 			Type:    resourceType,
 			Name:    resourceName,
-			Address: fmt.Sprintf("%s.%s", resourceType, resourceName),
+			Address: address + inst.addressKey(),
+			Index:   inst.index(),
 			Config:  config,
+			File:    block.DefRange.Filename,
+			Range:   blockRange(block),
+		})
+	}
+
+	return resources, diags
+}
+
+// parseProvider extracts a provider configuration block
+func parseProvider(block *hcl.Block, ctx *hcl.EvalContext, moduleAddress string) Provider {
+	config := bodyConfig(block.Body, ctx, moduleAddress)
+
+	alias, _ := config["alias"].(string)
+
+	return Provider{
+		Name:   block.Labels[0],
+		Alias:  alias,
+		Module: moduleAddress,
+		Config: config,
+		File:   block.DefRange.Filename,
+	}
+}
+
+// parseTerraformSettings reads the backend (or cloud block) and
+// required_providers from a terraform block into data
+func parseTerraformSettings(block *hcl.Block, ctx *hcl.EvalContext, data *TerraformData) {
+	config := bodyConfig(block.Body, ctx, "")
+
+	// backend "s3" { ... } is nested under its label: {"backend": {"s3": {...}}}
+	if backends, ok := config["backend"].(map[string]interface{}); ok {
+		for backendType, backendConfig := range backends {
+			cfg, _ := backendConfig.(map[string]interface{})
+			data.Backends = append(data.Backends, Backend{
+				Type:   backendType,
+				Config: cfg,
+				File:   block.DefRange.Filename,
+			})
+		}
+	}
+
+	if cloud, ok := config["cloud"].(map[string]interface{}); ok {
+		data.Backends = append(data.Backends, Backend{
+			Type:   "cloud",
+			Config: cloud,
+			File:   block.DefRange.Filename,
 		})
 	}
 
-	// Parse variables (simplified for MVP)
-	for _, block := range bodyContent.Blocks.OfType("variable") {
-		if len(block.Labels) > 0 {
-			data.Variables[block.Labels[0]] = Variable{
-				Name: block.Labels[0],
+	if required, ok := config["required_providers"].(map[string]interface{}); ok {
+		for name, req := range required {
+			switch r := req.(type) {
+			case string:
+				// Legacy form: aws = "~> 5.0"
+				data.RequiredProviders[name] = RequiredProvider{Version: r}
+			case map[string]interface{}:
+				source, _ := r["source"].(string)
+				version, _ := r["version"].(string)
+				data.RequiredProviders[name] = RequiredProvider{Source: source, Version: version}
 			}
 		}
 	}
+}
 
-	return data, nil
+// parseVariable reads a variable block's type, default, description and
+// sensitivity
+func parseVariable(block *hcl.Block) Variable {
+	decl := decodeVariable(block)
+	attrs, _ := block.Body.JustAttributes()
+
+	variable := Variable{
+		Name:        block.Labels[0],
+		Type:        "any",
+		Default:     ctyToGo(decl.defValue),
+		Description: stringAttribute(attrs, "description"),
+		Sensitive:   boolAttribute(attrs, "sensitive"),
+		File:        block.DefRange.Filename,
+		Range:       blockRange(block),
+	}
+	if decl.hasType {
+		variable.Type = typeexpr.TypeString(decl.typ)
+	}
+
+	return variable
 }
 
-// extractReference extracts Terraform references like aws_s3_bucket.example.id
-func extractReference(expr hcl.Expression) string {
-	// Try to get the expression as a traversal
-	traversal, diags := hcl.AbsTraversalForExpr(expr)
-	if !diags.HasErrors() {
-		// Convert traversal to string like "aws_s3_bucket.example.id"
-		var parts []string
-		for _, traverser := range traversal {
-			switch t := traverser.(type) {
-			case hcl.TraverseRoot:
-				parts = append(parts, t.Name)
-			case hcl.TraverseAttr:
-				parts = append(parts, t.Name)
-			case hcl.TraverseIndex:
-				// Handle index like [0] or ["key"]
-				if t.Key.Type() == cty.String {
-					parts = append(parts, fmt.Sprintf("[%q]", t.Key.AsString()))
-				} else if t.Key.Type() == cty.Number {
-					num, _ := t.Key.AsBigFloat().Float64()
-					parts = append(parts, fmt.Sprintf("[%d]", int(num)))
+// parseOutput reads an output block. Value holds the evaluated value (left
+// empty for sensitive outputs), or the reference text when it can't be
+// evaluated; Expression is the value as written in src.
+func parseOutput(block *hcl.Block, ctx *hcl.EvalContext, src []byte) Output {
+	attrs, _ := block.Body.JustAttributes()
+
+	output := Output{
+		Name:        block.Labels[0],
+		Description: stringAttribute(attrs, "description"),
+		Sensitive:   boolAttribute(attrs, "sensitive"),
+		File:        block.DefRange.Filename,
+		Range:       blockRange(block),
+	}
+
+	if attr, ok := attrs["value"]; ok {
+		// Never carry the values of sensitive outputs into policy input
+		if val, ok := attributeValue(attr, ctx, ""); ok && !output.Sensitive {
+			output.Value = valueString(val)
+		}
+		if src != nil {
+			output.Expression = string(attr.Expr.Range().SliceBytes(src))
+		}
+	}
+
+	return output
+}
+
+// stringAttribute returns a literal string attribute, or "" if absent
+func stringAttribute(attrs hcl.Attributes, name string) string {
+	attr, ok := attrs[name]
+	if !ok {
+		return ""
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
+
+// boolAttribute returns a literal bool attribute, or false if absent
+func boolAttribute(attrs hcl.Attributes, name string) bool {
+	attr, ok := attrs[name]
+	if !ok {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || val.Type() != cty.Bool {
+		return false
+	}
+	return val.True()
+}
+
+// valueString renders a value as a string, using JSON for anything but strings
+func valueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// bodyConfig converts a block body into a map of its attributes and nested
+// blocks. A block appears as an object, or as an array of objects when the
+// same block type is repeated. Labeled blocks such as dynamic "ingress" are
+// nested under their labels: {"dynamic": {"ingress": {...}}}.
+func bodyConfig(body hcl.Body, ctx *hcl.EvalContext, moduleAddress string) map[string]interface{} {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return jsonBodyConfig(body, ctx, moduleAddress)
+	}
+
+	config := make(map[string]interface{})
+
+	for name, attr := range syntaxBody.Attributes {
+		if val, ok := attributeValue(attr.AsHCLAttribute(), ctx, moduleAddress); ok {
+			config[name] = val
+		}
+	}
+
+	for _, block := range syntaxBody.Blocks {
+		// Render dynamic blocks as the blocks they generate when possible
+		if block.Type == "dynamic" && len(block.Labels) == 1 {
+			if rendered, ok := expandDynamicBlock(block, ctx, moduleAddress); ok {
+				for _, blockConfig := range rendered {
+					addBlock(config, block.Labels[0], blockConfig)
 				}
+				continue
 			}
 		}
-		return strings.Join(parts, ".")
-	}
-
-	// Fallback: try to get source bytes from the expression
-	// This handles more complex expressions
-	if syntaxExpr, ok := expr.(*hclsyntax.ScopeTraversalExpr); ok {
-		// Build the reference from the traversal
-		var parts []string
-		for _, traverser := range syntaxExpr.Traversal {
-			switch t := traverser.(type) {
-			case hcl.TraverseRoot:
-				parts = append(parts, t.Name)
-			case hcl.TraverseAttr:
-				parts = append(parts, t.Name)
+
+		container := config
+		key := block.Type
+		for _, label := range block.Labels {
+			labeled, ok := container[key].(map[string]interface{})
+			if !ok {
+				labeled = make(map[string]interface{})
+				container[key] = labeled
 			}
+			container = labeled
+			key = label
 		}
-		if len(parts) > 0 {
-			return strings.Join(parts, ".")
+
+		addBlock(container, key, bodyConfig(block.Body, ctx, moduleAddress))
+	}
+
+	return config
+}
+
+// addBlock stores a nested block, turning repeated blocks into an array
+func addBlock(config map[string]interface{}, key string, block map[string]interface{}) {
+	switch existing := config[key].(type) {
+	case nil:
+		config[key] = block
+	case map[string]interface{}:
+		config[key] = []interface{}{existing, block}
+	case []interface{}:
+		config[key] = append(existing, block)
+	}
+}
+
+// attributeValue evaluates an attribute against ctx. Expressions that cannot
+// be resolved statically (e.g. references to other resources) fall back to
+// the reference text, such as "aws_s3_bucket.example.id", qualified with the
+// module address inside child modules.
+func attributeValue(attr *hcl.Attribute, ctx *hcl.EvalContext, moduleAddress string) (interface{}, bool) {
+	val, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() || !val.IsKnown() {
+		if refVal := extractReference(attr.Expr, ctx); refVal != "" {
+			return qualifyReference(refVal, moduleAddress), true
 		}
+		return nil, false
 	}
 
-	return ""
+	return ctyToGo(val), true
+}
+
+// qualifyReference prefixes a resource reference with the module it was made
+// in, so that references from different module instances stay distinct
+func qualifyReference(ref, moduleAddress string) string {
+	if moduleAddress == "" || !(isResourceReference(ref) || strings.HasPrefix(ref, "data.")) {
+		return ref
+	}
+	return moduleAddress + "." + ref
+}
+
+// blockRange returns the span of a block from its header to its closing brace
+func blockRange(block *hcl.Block) *SourceRange {
+	rng := block.DefRange
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		rng = hcl.RangeBetween(block.DefRange, body.SrcRange)
+	}
+
+	return &SourceRange{
+		StartLine:   rng.Start.Line,
+		StartColumn: rng.Start.Column,
+		EndLine:     rng.End.Line,
+		EndColumn:   rng.End.Column,
+	}
+}
+
+// extractReference extracts Terraform references like aws_s3_bucket.example.id.
+// Index keys such as [count.index] or [each.key] are resolved against ctx.
+func extractReference(expr hcl.Expression, ctx *hcl.EvalContext) string {
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		return formatTraversal(e.Traversal)
+
+	case *hclsyntax.RelativeTraversalExpr:
+		// e.g. aws_s3_bucket.example[count.index].id
+		source := extractReference(e.Source, ctx)
+		if source == "" {
+			return ""
+		}
+		return source + formatTraversal(e.Traversal)
+
+	case *hclsyntax.IndexExpr:
+		collection := extractReference(e.Collection, ctx)
+		if collection == "" {
+			return ""
+		}
+		key, diags := e.Key.Value(ctx)
+		if diags.HasErrors() || !key.IsKnown() || key.IsNull() {
+			return ""
+		}
+		index := formatIndex(key)
+		if index == "" {
+			return ""
+		}
+		return collection + index
+	}
+
+	// JSON syntax writes references as "${aws_s3_bucket.example.id}"
+	if inner := interpolatedExpression(expr); inner != nil {
+		return extractReference(inner, ctx)
+	}
+
+	// Other syntaxes can still be static traversals
+	traversal, diags := hcl.AbsTraversalForExpr(expr)
+	if diags.HasErrors() {
+		return ""
+	}
+	return formatTraversal(traversal)
+}
+
+// formatTraversal converts a traversal to a string like "aws_s3_bucket.example[0].id"
+func formatTraversal(traversal hcl.Traversal) string {
+	var sb strings.Builder
+	for _, traverser := range traversal {
+		switch t := traverser.(type) {
+		case hcl.TraverseRoot:
+			sb.WriteString(t.Name)
+		case hcl.TraverseAttr:
+			sb.WriteString(".")
+			sb.WriteString(t.Name)
+		case hcl.TraverseIndex:
+			// Handle index like [0] or ["key"]
+			sb.WriteString(formatIndex(t.Key))
+		}
+	}
+	return sb.String()
 }
 
 // ctyToGo converts cty.Value to Go types
 func ctyToGo(val cty.Value) interface{} {
-	if val.IsNull() {
+	if !val.IsKnown() || val.IsNull() {
 		return nil
 	}
 
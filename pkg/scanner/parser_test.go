@@ -0,0 +1,583 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files, by slash-separated path, in a temporary
+// directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// parseDir parses the .tf files directly in dir
+func parseDir(t *testing.T, dir string, opts ParseOptions) *TerraformData {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ParseTerraformFiles(paths, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// addresses returns the sorted addresses of resources
+func addresses(resources []Resource) []string {
+	result := []string{}
+	for _, r := range resources {
+		result = append(result, r.Address)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// findResource returns the resource at address, failing the test when
+// there is none
+func findResource(t *testing.T, data *TerraformData, address string) Resource {
+	t.Helper()
+	for _, r := range data.Resources {
+		if r.Address == address {
+			return r
+		}
+	}
+	t.Fatalf("no resource %s in %v", address, addresses(data.Resources))
+	return Resource{}
+}
+
+// errorMessages joins the messages of errs for matching
+func errorMessages(errs []ScanError) string {
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestParseTerraformFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     map[string]interface{} // config of the only resource
+		wantErr  bool
+	}{
+		{
+			name:     "attributes and blocks",
+			filename: "main.tf",
+			content: `
+resource "aws_s3_bucket" "logs" {
+  bucket = "acme-logs"
+  tags = {
+    Owner = "data-team"
+  }
+}`,
+			want: map[string]interface{}{
+				"bucket": "acme-logs",
+				"tags":   map[string]interface{}{"Owner": "data-team"},
+			},
+		},
+		{
+			name:     "references",
+			filename: "main.tf",
+			content: `
+resource "aws_s3_bucket_versioning" "logs" {
+  bucket = aws_s3_bucket.logs.id
+}`,
+			want: map[string]interface{}{"bucket": "aws_s3_bucket.logs.id"},
+		},
+		{
+			name:     "json syntax",
+			filename: "main.tf.json",
+			content:  `{"resource": {"aws_s3_bucket": {"logs": {"bucket": "acme-logs"}}}}`,
+			want:     map[string]interface{}{"bucket": "acme-logs"},
+		},
+		{
+			name:     "syntax error",
+			filename: "main.tf",
+			content:  `resource "aws_s3_bucket" "logs" {`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseTerraformFile([]byte(tt.content), tt.filename)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Resources) != 1 {
+				t.Fatalf("got %d resources, want 1", len(data.Resources))
+			}
+			if got := data.Resources[0].Config; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariablesAndLocals(t *testing.T) {
+	files := map[string]string{
+		"variables.tf": `
+variable "env" {
+  default = "dev"
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}`,
+		"main.tf": `
+locals {
+  name   = "acme-${var.env}"
+  bucket = "${local.name}-logs"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = local.bucket
+  tags = {
+    Replicas = var.replicas
+  }
+}`,
+	}
+
+	tests := []struct {
+		name         string
+		tfvars       map[string]string // extra files, e.g. terraform.tfvars
+		opts         func(dir string) ParseOptions
+		wantBucket   string
+		wantReplicas float64
+	}{
+		{
+			name:         "defaults",
+			wantBucket:   "acme-dev-logs",
+			wantReplicas: 1,
+		},
+		{
+			name:         "terraform.tfvars",
+			tfvars:       map[string]string{"terraform.tfvars": `env = "staging"`},
+			wantBucket:   "acme-staging-logs",
+			wantReplicas: 1,
+		},
+		{
+			name: "auto.tfvars after terraform.tfvars",
+			tfvars: map[string]string{
+				"terraform.tfvars":       `env = "staging"`,
+				"production.auto.tfvars": `env = "production"`,
+			},
+			wantBucket:   "acme-production-logs",
+			wantReplicas: 1,
+		},
+		{
+			name:   "var file option",
+			tfvars: map[string]string{"vars/prod.tfvars": `replicas = 3`},
+			opts: func(dir string) ParseOptions {
+				return ParseOptions{VarFiles: []string{filepath.Join(dir, "vars", "prod.tfvars")}}
+			},
+			wantBucket:   "acme-dev-logs",
+			wantReplicas: 3,
+		},
+		{
+			name:   "vars override files",
+			tfvars: map[string]string{"terraform.tfvars": `env = "staging"`},
+			opts: func(string) ParseOptions {
+				return ParseOptions{Vars: map[string]string{"env": "qa", "replicas": "2"}}
+			},
+			wantBucket:   "acme-qa-logs",
+			wantReplicas: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := make(map[string]string)
+			for name, content := range files {
+				all[name] = content
+			}
+			for name, content := range tt.tfvars {
+				all[name] = content
+			}
+			dir := writeFiles(t, all)
+
+			var opts ParseOptions
+			if tt.opts != nil {
+				opts = tt.opts(dir)
+			}
+			data := parseDir(t, dir, opts)
+			if len(data.Errors) > 0 {
+				t.Fatalf("unexpected errors: %s", errorMessages(data.Errors))
+			}
+
+			config := findResource(t, data, "aws_s3_bucket.logs").Config
+			if got := config["bucket"]; got != tt.wantBucket {
+				t.Errorf("bucket = %v, want %v", got, tt.wantBucket)
+			}
+			tags, _ := config["tags"].(map[string]interface{})
+			if got := tags["Replicas"]; got != tt.wantReplicas {
+				t.Errorf("Replicas = %#v, want %#v", got, tt.wantReplicas)
+			}
+		})
+	}
+}
+
+func TestUndeclaredVars(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.tf": `
+variable "env" {}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "acme-${var.env}"
+}`,
+	})
+	paths := []string{filepath.Join(dir, "main.tf")}
+
+	tests := []struct {
+		name string
+		vars map[string]string
+		want []string
+	}{
+		{"none", nil, []string{}},
+		{"declared", map[string]string{"env": "prod"}, []string{}},
+		{"undeclared", map[string]string{"env": "prod", "nope": "1", "enviroment": "x"}, []string{"enviroment", "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UndeclaredVars(paths, ParseOptions{Vars: tt.vars})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil {
+				got = []string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UndeclaredVars = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpansion(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      []string
+		wantError string // substring of a parse error, if any
+	}{
+		{
+			name: "not repeated",
+			content: `
+resource "aws_ebs_volume" "data" {
+  size = 10
+}`,
+			want: []string{"aws_ebs_volume.data"},
+		},
+		{
+			name: "count",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = 3
+  size  = 10
+}`,
+			want: []string{"aws_ebs_volume.data[0]", "aws_ebs_volume.data[1]", "aws_ebs_volume.data[2]"},
+		},
+		{
+			name: "count of zero",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = 0
+}`,
+			want: []string{},
+		},
+		{
+			name: "count from a variable",
+			content: `
+variable "replicas" {
+  default = 2
+}
+
+resource "aws_ebs_volume" "data" {
+  count = var.replicas
+}`,
+			want: []string{"aws_ebs_volume.data[0]", "aws_ebs_volume.data[1]"},
+		},
+		{
+			name: "unknown count",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = length(aws_instance.web.ebs_block_device)
+}`,
+			want: []string{"aws_ebs_volume.data"},
+		},
+		{
+			name: "negative count",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = -1
+}`,
+			want:      []string{"aws_ebs_volume.data"},
+			wantError: "count must be a whole number of 0 or more, got -1",
+		},
+		{
+			name: "fractional count",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = 1.5
+}`,
+			want:      []string{"aws_ebs_volume.data"},
+			wantError: "count must be a whole number of 0 or more, got 1.5",
+		},
+		{
+			name: "count over the instance cap",
+			content: `
+resource "aws_ebs_volume" "data" {
+  count = 1000000000
+}`,
+			want:      []string{"aws_ebs_volume.data"},
+			wantError: "count of 1000000000 exceeds the limit of 10000 instances",
+		},
+		{
+			name: "for_each over a map",
+			content: `
+resource "aws_s3_bucket" "fleet" {
+  for_each = { logs = "a", exports = "b" }
+  bucket   = "acme-${each.key}"
+}`,
+			want: []string{`aws_s3_bucket.fleet["exports"]`, `aws_s3_bucket.fleet["logs"]`},
+		},
+		{
+			name: "for_each over a set",
+			content: `
+resource "aws_s3_bucket" "fleet" {
+  for_each = toset(["logs", "exports"])
+}`,
+			want: []string{`aws_s3_bucket.fleet["exports"]`, `aws_s3_bucket.fleet["logs"]`},
+		},
+		{
+			name: "for_each over the instance cap",
+			content: `
+resource "aws_s3_bucket" "fleet" {
+  for_each = toset(flatten([for i in range(101) : [for j in range(100) : "${i}-${j}"]]))
+}`,
+			want:      []string{"aws_s3_bucket.fleet"},
+			wantError: "for_each of 10100 elements exceeds the limit of 10000 instances",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"main.tf": tt.content})
+			data := parseDir(t, dir, ParseOptions{})
+
+			if got := addresses(data.Resources); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addresses = %v, want %v", got, tt.want)
+			}
+
+			messages := errorMessages(data.Errors)
+			if tt.wantError == "" && messages != "" {
+				t.Errorf("unexpected errors: %s", messages)
+			}
+			if !strings.Contains(messages, tt.wantError) {
+				t.Errorf("errors %q don't mention %q", messages, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestExpansionBindsCountAndEach(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"main.tf": `
+resource "aws_ebs_volume" "data" {
+  count     = 2
+  encrypted = count.index == 0
+}
+
+resource "aws_s3_bucket" "fleet" {
+  for_each = { logs = "acme-logs" }
+  bucket   = each.value
+}`})
+	data := parseDir(t, dir, ParseOptions{})
+
+	tests := []struct {
+		address string
+		attr    string
+		want    interface{}
+	}{
+		{"aws_ebs_volume.data[0]", "encrypted", true},
+		{"aws_ebs_volume.data[1]", "encrypted", false},
+		{`aws_s3_bucket.fleet["logs"]`, "bucket", "acme-logs"},
+	}
+	for _, tt := range tests {
+		if got := findResource(t, data, tt.address).Config[tt.attr]; got != tt.want {
+			t.Errorf("%s.%s = %#v, want %#v", tt.address, tt.attr, got, tt.want)
+		}
+	}
+}
+
+func TestModules(t *testing.T) {
+	bucketModule := `
+variable "name" {}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}`
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		want      map[string]interface{} // bucket name by address
+		wantError string
+	}{
+		{
+			name: "local module",
+			files: map[string]string{
+				"main.tf": `
+module "logs" {
+  source = "./modules/bucket"
+  name   = "acme-logs"
+}`,
+				"modules/bucket/main.tf": bucketModule,
+			},
+			want: map[string]interface{}{"module.logs.aws_s3_bucket.this": "acme-logs"},
+		},
+		{
+			name: "module with for_each",
+			files: map[string]string{
+				"main.tf": `
+module "buckets" {
+  source   = "./modules/bucket"
+  for_each = toset(["logs", "exports"])
+  name     = "acme-${each.key}"
+}`,
+				"modules/bucket/main.tf": bucketModule,
+			},
+			want: map[string]interface{}{
+				`module.buckets["exports"].aws_s3_bucket.this`: "acme-exports",
+				`module.buckets["logs"].aws_s3_bucket.this`:    "acme-logs",
+			},
+		},
+		{
+			name: "module not installed",
+			files: map[string]string{
+				"main.tf": `
+module "logs" {
+  source = "./modules/missing"
+}
+
+resource "aws_s3_bucket" "root" {
+  bucket = "acme-root"
+}`,
+			},
+			want: map[string]interface{}{"aws_s3_bucket.root": "acme-root"},
+		},
+		{
+			name: "module calling itself",
+			files: map[string]string{
+				"main.tf": `
+module "self" {
+  source = "./"
+}`,
+			},
+			want:      map[string]interface{}{},
+			wantError: "module cycle",
+		},
+		{
+			name: "modules calling each other",
+			files: map[string]string{
+				"main.tf": `
+module "a" {
+  source = "./modules/a"
+}`,
+				"modules/a/main.tf": `
+module "b" {
+  source = "../b"
+}`,
+				"modules/b/main.tf": `
+module "a" {
+  source = "../a"
+}`,
+			},
+			want:      map[string]interface{}{},
+			wantError: "module cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			data := parseDir(t, dir, ParseOptions{})
+
+			got := make(map[string]interface{})
+			for _, r := range data.Resources {
+				got[r.Address] = r.Config["bucket"]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resources = %v, want %v", got, tt.want)
+			}
+
+			messages := errorMessages(data.Errors)
+			if tt.wantError == "" && messages != "" {
+				t.Errorf("unexpected errors: %s", messages)
+			}
+			if !strings.Contains(messages, tt.wantError) {
+				t.Errorf("errors %q don't mention %q", messages, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestBrokenModuleFileExcludedFromEveryCall(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.tf": `
+module "a" {
+  source = "./modules/bucket"
+}
+
+module "b" {
+  source = "./modules/bucket"
+}`,
+		"modules/bucket/main.tf": `
+resource "aws_s3_bucket" "this" {
+  bucket = "acme"
+}`,
+		"modules/bucket/broken.tf": `
+resource "aws_s3_bucket" "broken" {
+  bucket = "acme-broken"
+`,
+	})
+	data := parseDir(t, dir, ParseOptions{})
+
+	want := []string{"module.a.aws_s3_bucket.this", "module.b.aws_s3_bucket.this"}
+	if got := addresses(data.Resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("addresses = %v, want %v", got, want)
+	}
+	if len(data.Errors) == 0 {
+		t.Error("expected the broken file to be reported")
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tfPlan is the subset of `terraform show -json` output used by the scanner
+type tfPlan struct {
+	FormatVersion   string                    `json:"format_version"`
+	Variables       map[string]tfPlanVariable `json:"variables"`
+	PlannedValues   *tfPlanValues             `json:"planned_values"`
+	ResourceChanges []tfResourceChange        `json:"resource_changes"`
+	Configuration   *tfPlanConfiguration      `json:"configuration"`
+}
+
+type tfPlanVariable struct {
+	Value interface{} `json:"value"`
+}
+
+type tfPlanValues struct {
+	Outputs    map[string]tfPlanOutput `json:"outputs"`
+	RootModule tfPlanModule            `json:"root_module"`
+}
+
+type tfPlanOutput struct {
+	Sensitive bool        `json:"sensitive"`
+	Value     interface{} `json:"value"`
+}
+
+type tfPlanModule struct {
+	Address      string           `json:"address"`
+	Resources    []tfPlanResource `json:"resources"`
+	ChildModules []tfPlanModule   `json:"child_modules"`
+}
+
+type tfPlanResource struct {
+	Address       string                 `json:"address"`
+	Mode          string                 `json:"mode"`
+	Type          string                 `json:"type"`
+	Name          string                 `json:"name"`
+	Index         interface{}            `json:"index"`
+	ModuleAddress string                 `json:"module_address"`
+	Values        map[string]interface{} `json:"values"`
+
+	// SensitiveValues mirrors Values, with true for sensitive values
+	SensitiveValues interface{} `json:"sensitive_values"`
+}
+
+type tfResourceChange struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Change        struct {
+		Actions        []string               `json:"actions"`
+		After          map[string]interface{} `json:"after"`
+		AfterSensitive interface{}            `json:"after_sensitive"`
+	} `json:"change"`
+}
+
+type tfPlanConfiguration struct {
+	RootModule tfConfigModule `json:"root_module"`
+}
+
+type tfConfigModule struct {
+	Resources   []tfConfigResource      `json:"resources"`
+	ModuleCalls map[string]tfModuleCall `json:"module_calls"`
+}
+
+type tfModuleCall struct {
+	Module tfConfigModule `json:"module"`
+}
+
+type tfConfigResource struct {
+	Address     string                 `json:"address"`
+	Mode        string                 `json:"mode"`
+	Expressions map[string]interface{} `json:"expressions"`
+}
+
+// moduleIndexPattern matches instance keys in module addresses, e.g. module.a["x"]
+var moduleIndexPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// ParsePlan converts a Terraform plan in JSON format (`terraform show -json`)
+// into TerraformData. Planned values are fully resolved, so variables, locals
+// and module inputs are already applied.
+func ParsePlan(content []byte) (*TerraformData, error) {
+	var plan tfPlan
+	if err := json.Unmarshal(content, &plan); err != nil {
+		return nil, fmt.Errorf("decode plan: %w", err)
+	}
+
+	if plan.PlannedValues == nil && len(plan.ResourceChanges) == 0 {
+		return nil, fmt.Errorf("not a Terraform plan: missing planned_values and resource_changes")
+	}
+
+	expressions := make(map[string]map[string]interface{})
+	if plan.Configuration != nil {
+		collectExpressions(plan.Configuration.RootModule, "", expressions)
+	}
+
+	data := newTerraformData()
+
+	if plan.PlannedValues != nil {
+		collectPlanResources(plan.PlannedValues.RootModule, expressions, data)
+
+		for name, output := range plan.PlannedValues.Outputs {
+			out := Output{
+				Name:      name,
+				Sensitive: output.Sensitive,
+			}
+			// Never carry the values of sensitive outputs into policy input
+			if !output.Sensitive {
+				out.Value = valueString(output.Value)
+			}
+			data.Outputs[name] = out
+		}
+	} else {
+		// Older or filtered plans may only carry resource_changes
+		for _, rc := range plan.ResourceChanges {
+			if rc.Mode != "managed" || rc.Change.After == nil {
+				continue
+			}
+			redactSensitive(rc.Change.After, rc.Change.AfterSensitive)
+			data.Resources = append(data.Resources, planResource(
+				rc.Address, rc.Type, rc.Name, rc.ModuleAddress, rc.Change.After, expressions,
+			))
+		}
+	}
+
+	for name := range plan.Variables {
+		data.Variables[name] = Variable{
+			Name: name,
+		}
+	}
+
+	return data, nil
+}
+
+// collectPlanResources walks a planned module tree and appends its managed
+// resources and data sources
+func collectPlanResources(module tfPlanModule, expressions map[string]map[string]interface{}, data *TerraformData) {
+	for _, r := range module.Resources {
+		if r.Mode != "managed" && r.Mode != "data" {
+			continue
+		}
+		moduleAddress := r.ModuleAddress
+		if moduleAddress == "" {
+			moduleAddress = module.Address
+		}
+		redactSensitive(r.Values, r.SensitiveValues)
+		resource := planResource(r.Address, r.Type, r.Name, moduleAddress, r.Values, expressions)
+		resource.Index = planIndex(r.Index)
+		if r.Mode == "data" {
+			data.DataSources = append(data.DataSources, resource)
+		} else {
+			data.Resources = append(data.Resources, resource)
+		}
+	}
+
+	for _, child := range module.ChildModules {
+		collectPlanResources(child, expressions, data)
+	}
+}
+
+// planResource builds a Resource from planned values, restoring references to
+// other resources from the configuration so policies can match them up
+func planResource(address, resourceType, name, moduleAddress string, values map[string]interface{}, expressions map[string]map[string]interface{}) Resource {
+	config := normalizePlanValues(values)
+
+	configAddress := fmt.Sprintf("%s.%s", resourceType, name)
+	if moduleAddress != "" {
+		configAddress = moduleIndexPattern.ReplaceAllString(moduleAddress, "") + "." + configAddress
+	}
+	if exprs, ok := expressions[configAddress]; ok {
+		applyReferences(config, exprs, moduleAddress)
+	}
+
+	return Resource{
+		Type:    resourceType,
+		Name:    name,
+		Address: address,
+		Config:  config,
+	}
+}
+
+// collectExpressions indexes configuration expressions by module-qualified address
+func collectExpressions(module tfConfigModule, prefix string, out map[string]map[string]interface{}) {
+	for _, r := range module.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		out[prefix+r.Address] = r.Expressions
+	}
+
+	for name, call := range module.ModuleCalls {
+		collectExpressions(call.Module, fmt.Sprintf("%smodule.%s.", prefix, name), out)
+	}
+}
+
+// applyReferences replaces values that point at other managed resources with
+// the reference itself (e.g. "aws_s3_bucket.logs.id"), matching the HCL parser
+func applyReferences(config map[string]interface{}, expressions map[string]interface{}, moduleAddress string) {
+	for name, expr := range expressions {
+		switch e := expr.(type) {
+		case map[string]interface{}:
+			refs, ok := e["references"].([]interface{})
+			if !ok || len(refs) == 0 {
+				continue
+			}
+			ref, ok := refs[0].(string)
+			if !ok || !isResourceReference(ref) {
+				continue
+			}
+			config[name] = qualifyReference(ref, moduleAddress)
+
+		case []interface{}:
+			// Nested blocks: expressions and values are parallel lists
+			blocks, ok := config[name].([]interface{})
+			if !ok {
+				continue
+			}
+			for i := range e {
+				if i >= len(blocks) {
+					break
+				}
+				blockExprs, ok := e[i].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if blockConfig, ok := blocks[i].(map[string]interface{}); ok {
+					applyReferences(blockConfig, blockExprs, moduleAddress)
+				}
+			}
+		}
+	}
+}
+
+// isResourceReference reports whether ref points at a managed resource rather
+// than a variable, local, data source or module output
+func isResourceReference(ref string) bool {
+	root := strings.SplitN(ref, ".", 2)[0]
+	switch root {
+	case "var", "local", "module", "data", "each", "count", "path", "terraform", "self":
+		return false
+	}
+	return strings.Contains(root, "_")
+}
+
+// redactedValue replaces sensitive attribute values in policy input
+const redactedValue = "(sensitive)"
+
+// redactSensitive replaces the values that sensitive, a structure mirroring
+// value with true at each sensitive value (after_sensitive and
+// sensitive_values in plans), marks. Attributes stay present, so policies can
+// still tell that a password is set, but secrets never reach policy input.
+func redactSensitive(value, sensitive interface{}) interface{} {
+	switch s := sensitive.(type) {
+	case bool:
+		if s && value != nil {
+			return redactedValue
+		}
+	case map[string]interface{}:
+		if v, ok := value.(map[string]interface{}); ok {
+			for key, marks := range s {
+				if item, ok := v[key]; ok {
+					v[key] = redactSensitive(item, marks)
+				}
+			}
+		}
+	case []interface{}:
+		if v, ok := value.([]interface{}); ok {
+			for i, marks := range s {
+				if i < len(v) {
+					v[i] = redactSensitive(v[i], marks)
+				}
+			}
+		}
+	}
+	return value
+}
+
+// normalizePlanValues drops null and empty values so that undeclared blocks
+// look the same as they do when parsing HCL
+func normalizePlanValues(values map[string]interface{}) map[string]interface{} {
+	config := make(map[string]interface{})
+	for k, v := range values {
+		if nv := normalizePlanValue(v); nv != nil {
+			config[k] = nv
+		}
+	}
+	return config
+}
+
+func normalizePlanValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return normalizePlanValues(val)
+	case []interface{}:
+		if len(val) == 0 {
+			return nil
+		}
+		result := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			if nv := normalizePlanValue(item); nv != nil {
+				result = append(result, nv)
+			}
+		}
+		return result
+	}
+	return v
+}
+
+// planIndex converts a decoded instance key so count indexes are integers
+func planIndex(index interface{}) interface{} {
+	if f, ok := index.(float64); ok {
+		return int(f)
+	}
+	return index
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlanRedactsSensitiveValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "planned values",
+			content: `{
+  "format_version": "1.2",
+  "planned_values": {"root_module": {"resources": [{
+    "address": "aws_db_instance.main",
+    "mode": "managed",
+    "type": "aws_db_instance",
+    "name": "main",
+    "values": {
+      "engine": "postgres",
+      "password": "hunter2",
+      "tags": {"Owner": "data-team", "Secret": "s3cr3t"},
+      "ingress": [{"cidr": "10.0.0.0/8"}, {"cidr": "0.0.0.0/0"}],
+      "kms_key_id": null
+    },
+    "sensitive_values": {
+      "password": true,
+      "tags": {"Secret": true},
+      "ingress": [{}, {"cidr": true}],
+      "kms_key_id": true
+    }
+  }]}}
+}`,
+		},
+		{
+			name: "resource changes",
+			content: `{
+  "format_version": "1.2",
+  "resource_changes": [{
+    "address": "aws_db_instance.main",
+    "mode": "managed",
+    "type": "aws_db_instance",
+    "name": "main",
+    "change": {
+      "actions": ["create"],
+      "after": {
+        "engine": "postgres",
+        "password": "hunter2",
+        "tags": {"Owner": "data-team", "Secret": "s3cr3t"},
+        "ingress": [{"cidr": "10.0.0.0/8"}, {"cidr": "0.0.0.0/0"}],
+        "kms_key_id": null
+      },
+      "after_sensitive": {
+        "password": true,
+        "tags": {"Secret": true},
+        "ingress": [{}, {"cidr": true}],
+        "kms_key_id": true
+      }
+    }
+  }]
+}`,
+		},
+	}
+
+	want := map[string]interface{}{
+		"engine":   "postgres",
+		"password": redactedValue,
+		"tags":     map[string]interface{}{"Owner": "data-team", "Secret": redactedValue},
+		"ingress": []interface{}{
+			map[string]interface{}{"cidr": "10.0.0.0/8"},
+			map[string]interface{}{"cidr": redactedValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParsePlan([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Resources) != 1 {
+				t.Fatalf("got %d resources, want 1", len(data.Resources))
+			}
+			if got := data.Resources[0].Config; !reflect.DeepEqual(got, want) {
+				t.Errorf("config = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
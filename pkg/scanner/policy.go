@@ -0,0 +1,228 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// PolicyConfig selects the policies an OPAEvaluator compiles
+type PolicyConfig struct {
+	// FS is a policy bundle, such as the one embedded in the binary. May be
+	// nil.
+	FS fs.FS
+
+	// Packs are the directories of FS to load, e.g. checks; empty loads all
+	// of FS
+	Packs []string
+
+	// Paths are Rego files or directories on disk loaded on top of FS. A
+	// file replaces the one in FS with the same path relative to its pack
+	// and the same package; other files are added. A crosswalk.yaml among
+	// them overrides the metadata of the checks it lists.
+	Paths []string
+
+	// Frameworks are the IDs of the frameworks whose controls findings are
+	// reported against. Findings of checks mapped only to other frameworks
+	// are dropped. Empty selects DefaultFramework.
+	Frameworks []string
+
+	// Entrypoints are the rules to query, e.g. data.acme.evaluate. Each must
+	// produce {violations, warnings, passed} like the bundled policies. When
+	// empty, the evaluate rule of every package that defines one is queried.
+	Entrypoints []string
+
+	// EnabledChecks, when set, keeps only the findings of checks whose IDs
+	// match one of these patterns, in which * matches anything.
+	// DisabledChecks drops the findings of the checks matching any of its
+	// patterns. Findings without a check ID are always kept.
+	EnabledChecks  []string
+	DisabledChecks []string
+
+	// Severities overrides the severity of the violations and warnings of
+	// checks, by check ID
+	Severities map[string]string
+
+	// Settings are passed to policies as input.config, e.g. required_tags
+	Settings map[string]interface{}
+}
+
+// policyModule is a parsed Rego source file
+type policyModule struct {
+	key    string // path relative to the pack or policy directory
+	name   string // file name shown in compile errors
+	module *ast.Module
+}
+
+// policySet collects the Rego modules to compile, the crosswalk of their
+// checks and the descriptions of the controls they map to
+type policySet struct {
+	modules   []policyModule
+	overrides Crosswalk // from crosswalk files
+	crosswalk Crosswalk // from annotations, with overrides; set by compile
+	controls  ControlInfos
+}
+
+// loadPolicies loads the policies cfg selects
+func loadPolicies(cfg PolicyConfig) (*policySet, error) {
+	policies := &policySet{}
+	if cfg.FS != nil {
+		packs := cfg.Packs
+		if len(packs) == 0 {
+			packs = []string{"."}
+		}
+		for _, pack := range packs {
+			if err := policies.addFS(cfg.FS, pack, "embedded"); err != nil {
+				return nil, fmt.Errorf("load embedded policies: %w", err)
+			}
+		}
+	}
+	for _, p := range cfg.Paths {
+		if err := policies.addPath(p); err != nil {
+			return nil, err
+		}
+	}
+	if len(policies.modules) == 0 {
+		return nil, fmt.Errorf("no .rego policies found")
+	}
+	return policies, nil
+}
+
+// addFS adds every .rego file under dir in fsys. Names in compile errors are
+// prefixed with origin.
+func (p *policySet) addFS(fsys fs.FS, dir, origin string) error {
+	return fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(isPolicyFile(name) || isMetadataFile(name)) {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("read policy %s: %w", name, err)
+		}
+		if isMetadataFile(name) {
+			return p.addMetadata(path.Join(origin, name), content)
+		}
+		key := strings.TrimPrefix(name, dir+"/")
+		if dir == "." {
+			key = name
+		}
+		return p.add(key, path.Join(origin, name), content)
+	})
+}
+
+// addPath adds a .rego file, crosswalk or control catalog, or every one
+// under a directory
+func (p *policySet) addPath(policyPath string) error {
+	info, err := os.Stat(policyPath)
+	if err != nil {
+		return fmt.Errorf("load policies: %w", err)
+	}
+
+	if info.IsDir() {
+		return p.addFS(os.DirFS(policyPath), ".", filepath.ToSlash(policyPath))
+	}
+
+	content, err := os.ReadFile(policyPath)
+	if err != nil {
+		return fmt.Errorf("read policy %s: %w", policyPath, err)
+	}
+	if isMetadataFile(policyPath) {
+		return p.addMetadata(policyPath, content)
+	}
+	return p.add(filepath.Base(policyPath), policyPath, content)
+}
+
+// add parses a module and adds it, replacing a module loaded earlier with the
+// same key and package
+func (p *policySet) add(key, name string, content []byte) error {
+	module, err := ast.ParseModuleWithOpts(name, string(content), ast.ParserOptions{ProcessAnnotation: true})
+	if err != nil {
+		return fmt.Errorf("parse policy: %w", err)
+	}
+	if module == nil {
+		// Empty file
+		return nil
+	}
+
+	m := policyModule{key: key, name: name, module: module}
+	for i, existing := range p.modules {
+		if existing.key == key && existing.module.Package.Path.Equal(module.Package.Path) {
+			p.modules[i] = m
+			return nil
+		}
+	}
+	p.modules = append(p.modules, m)
+	return nil
+}
+
+// addMetadata parses a crosswalk or control catalog and merges it over the
+// entries loaded earlier
+func (p *policySet) addMetadata(name string, content []byte) error {
+	if filepath.Base(name) == ControlsFile {
+		controls, err := ParseControls(content)
+		if err != nil {
+			return fmt.Errorf("parse control catalog %s: %w", name, err)
+		}
+		if p.controls == nil {
+			p.controls = make(ControlInfos)
+		}
+		p.controls.Merge(controls)
+		return nil
+	}
+
+	crosswalk, err := ParseCrosswalk(content)
+	if err != nil {
+		return fmt.Errorf("parse crosswalk %s: %w", name, err)
+	}
+	if p.overrides == nil {
+		p.overrides = make(Crosswalk)
+	}
+	p.overrides.Merge(crosswalk)
+	return nil
+}
+
+// compile compiles the modules together and builds the crosswalk from the
+// METADATA annotations of their checks, with crosswalk files merged over it
+func (p *policySet) compile() (*ast.Compiler, error) {
+	modules := make(map[string]*ast.Module, len(p.modules))
+	for _, m := range p.modules {
+		modules[m.name] = m.module
+	}
+
+	compiler := ast.NewCompiler()
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, compiler.Errors
+	}
+
+	crosswalk, err := checkMetadata(compiler.GetAnnotationSet())
+	if err != nil {
+		return nil, err
+	}
+	crosswalk.Merge(p.overrides)
+	p.crosswalk = crosswalk
+	return compiler, nil
+}
+
+// isMetadataFile reports whether a file is a crosswalk or control catalog
+func isMetadataFile(name string) bool {
+	base := filepath.Base(name)
+	return base == CrosswalkFile || base == ControlsFile
+}
+
+// isPolicyFile reports whether a file is a Rego policy; Rego tests are
+// left out
+func isPolicyFile(name string) bool {
+	return strings.HasSuffix(name, ".rego") && !strings.HasSuffix(name, "_test.rego")
+}
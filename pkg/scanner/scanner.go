@@ -4,49 +4,184 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// skippedDirs are directories never scanned for configuration: .terraform
+// holds downloaded modules (reached through module calls instead) and
+// node_modules the dependencies of CDKTF projects
+var skippedDirs = map[string]bool{
+	".terraform":   true,
+	"node_modules": true,
+}
+
 // Scanner is the main compliance scanner
 type Scanner struct {
-	evaluator *OPAEvaluator
+	evaluator    *OPAEvaluator
+	parseOptions ParseOptions
+	progress     ProgressFunc
+	exceptions   []Exception
+	baseline     *Baseline
+	exclude      []string
 }
 
-// New creates a new Scanner
+// New creates a Scanner reporting the embedded checks against SOC2, with
+// policies overridden or extended by the files in policyPaths
 func New(policyPaths []string) (*Scanner, error) {
-	evaluator, err := NewOPAEvaluator(policyPaths)
+	return NewWithOptions(Options{PolicyPaths: policyPaths})
+}
+
+// NewWithOptions creates a Scanner configured by opts
+func NewWithOptions(opts Options) (*Scanner, error) {
+	return NewWithOptionsContext(context.Background(), opts)
+}
+
+// NewWithOptionsContext is NewWithOptions with a context for cancelling
+// policy compilation
+func NewWithOptionsContext(ctx context.Context, opts Options) (*Scanner, error) {
+	for _, id := range opts.Frameworks {
+		if _, err := LookupFramework(id); err != nil {
+			return nil, err
+		}
+	}
+
+	evaluator, err := NewOPAEvaluatorWithConfig(ctx, opts.policyConfig())
 	if err != nil {
 		return nil, fmt.Errorf("initialize OPA: %w", err)
 	}
 
 	return &Scanner{
-		evaluator: evaluator,
+		evaluator:    evaluator,
+		parseOptions: opts.Parse,
+		progress:     opts.Progress,
+		exceptions:   opts.Exceptions,
+		baseline:     opts.Baseline,
+		exclude:      opts.Exclude,
 	}, nil
 }
 
+// SetParseOptions sets the variable files and values used when evaluating
+// Terraform expressions
+func (s *Scanner) SetParseOptions(opts ParseOptions) {
+	s.parseOptions = opts
+}
+
+// report passes a progress update to the callback, if there is one
+func (s *Scanner) report(stage ProgressStage, path string, files int) {
+	if s.progress != nil {
+		s.progress(Progress{Stage: stage, Path: path, Files: files})
+	}
+}
+
 // Scan performs a compliance scan on Terraform content
 func (s *Scanner) Scan(tfContent []byte) (*Result, error) {
+	return s.ScanContext(context.Background(), tfContent)
+}
+
+// ScanContext is Scan with a context for cancellation
+func (s *Scanner) ScanContext(ctx context.Context, tfContent []byte) (*Result, error) {
 	// 1. Parse Terraform
 	data, err := ParseTerraform(tfContent)
 	if err != nil {
 		return nil, fmt.Errorf("parse terraform: %w", err)
 	}
+	s.report(ProgressParsed, "", 1)
 
 	// 2. Evaluate with OPA
-	result, err := s.evaluator.Evaluate(data)
+	return s.evaluate(ctx, data)
+}
+
+// evaluate runs the policies against already parsed Terraform data
+func (s *Scanner) evaluate(ctx context.Context, data *TerraformData) (*Result, error) {
+	result, err := s.evaluator.EvaluateContext(ctx, data)
 	if err != nil {
 		return nil, fmt.Errorf("evaluate policies: %w", err)
 	}
+	result.Errors = append(result.Errors, data.Errors...)
+	s.applyExceptions(result)
+	s.report(ProgressEvaluated, "", 0)
 
 	return result, nil
 }
 
+// applyExceptions applies the configured exceptions and then the baseline,
+// which leaves the score as exceptions made it
+func (s *Scanner) applyExceptions(result *Result) {
+	if s.exceptions != nil {
+		result.ApplyExceptions(s.exceptions, time.Now())
+	}
+	if s.baseline != nil {
+		result.ApplyBaseline(s.baseline)
+	}
+}
+
+// scanFiles parses each file on its own and evaluates the merged result
+func (s *Scanner) scanFiles(ctx context.Context, paths []string) (*Result, error) {
+	data, err := parseTerraformFiles(ctx, paths, s.parseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("parse terraform: %w", err)
+	}
+	s.report(ProgressParsed, "", len(paths))
+
+	return s.evaluate(ctx, data)
+}
+
+// ScanPlan scans a Terraform plan exported with `terraform show -json`
+func (s *Scanner) ScanPlan(path string) (*Result, error) {
+	return s.ScanPlanContext(context.Background(), path)
+}
+
+// ScanPlanContext is ScanPlan with a context for cancellation
+func (s *Scanner) ScanPlanContext(ctx context.Context, path string) (*Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+
+	data, err := ParsePlan(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	s.report(ProgressParsed, path, 1)
+
+	return s.evaluate(ctx, data)
+}
+
+// ScanState scans a Terraform state file, checking what is actually deployed
+func (s *Scanner) ScanState(path string) (*Result, error) {
+	return s.ScanStateContext(context.Background(), path)
+}
+
+// ScanStateContext is ScanState with a context for cancellation
+func (s *Scanner) ScanStateContext(ctx context.Context, path string) (*Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	data, err := ParseState(content, path)
+	if err != nil {
+		return nil, fmt.Errorf("parse state: %w", err)
+	}
+	s.report(ProgressParsed, path, 1)
+
+	return s.evaluate(ctx, data)
+}
+
 // ScanPath scans a file or directory of Terraform files
 func (s *Scanner) ScanPath(path string) (*Result, error) {
+	return s.ScanPathContext(context.Background(), path)
+}
+
+// ScanPathContext is ScanPath with a context for cancellation
+func (s *Scanner) ScanPathContext(ctx context.Context, path string) (*Result, error) {
 	// Check if path exists
 	info, err := os.Stat(path)
 	if err != nil {
@@ -55,53 +190,142 @@ func (s *Scanner) ScanPath(path string) (*Result, error) {
 
 	// If it's a single file, scan it directly
 	if !info.IsDir() {
-		content, err := os.ReadFile(path)
+		s.report(ProgressDiscovered, path, 1)
+		return s.scanFiles(ctx, []string{path})
+	}
+
+	// If it's a directory, scan all Terraform files
+	return s.ScanDirectoryContext(ctx, path)
+}
+
+// ScanDirectory scans all Terraform files in a directory and subdirectories,
+// in native (.tf) or JSON (.tf.json) syntax. CDKTF projects are picked up
+// through the stacks that cdktf synth writes under cdktf.out/stacks.
+func (s *Scanner) ScanDirectory(dirPath string) (*Result, error) {
+	return s.ScanDirectoryContext(context.Background(), dirPath)
+}
+
+// ScanDirectoryContext is ScanDirectory with a context for cancellation
+func (s *Scanner) ScanDirectoryContext(ctx context.Context, dirPath string) (*Result, error) {
+	paths, err := findTerraformFiles(ctx, dirPath, s.exclude)
+	if err != nil {
+		return nil, err
+	}
+	s.report(ProgressDiscovered, dirPath, len(paths))
+
+	// Parse each file separately so findings keep their source location
+	return s.scanFiles(ctx, paths)
+}
+
+// ScanFiles scans multiple specific files
+func (s *Scanner) ScanFiles(paths []string) (*Result, error) {
+	return s.ScanFilesContext(context.Background(), paths)
+}
+
+// ScanFilesContext is ScanFiles with a context for cancellation
+func (s *Scanner) ScanFilesContext(ctx context.Context, paths []string) (*Result, error) {
+	s.report(ProgressDiscovered, "", len(paths))
+
+	return s.scanFiles(ctx, paths)
+}
+
+// UndeclaredVars returns the names of the variable values set through
+// ParseOptions.Vars that no root module in paths, files or directories,
+// declares. See the package function UndeclaredVars.
+func (s *Scanner) UndeclaredVars(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat path: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		found, err := findTerraformFiles(context.Background(), path, s.exclude)
 		if err != nil {
-			return nil, fmt.Errorf("read file: %w", err)
+			return nil, err
 		}
-		return s.Scan(content)
+		files = append(files, found...)
 	}
 
-	// If it's a directory, scan all .tf files
-	return s.ScanDirectory(path)
+	return UndeclaredVars(files, s.parseOptions)
 }
 
-// ScanDirectory scans all .tf files in a directory and subdirectories
-func (s *Scanner) ScanDirectory(dirPath string) (*Result, error) {
-	var allContent []byte
-	fileCount := 0
+// Drift compares the configuration at path (a file or directory) with the
+// resources recorded in a Terraform state file, reporting drift against the
+// selected frameworks through the crosswalk. See CompareState.
+func (s *Scanner) Drift(path, statePath string) (*Result, error) {
+	return s.DriftContext(context.Background(), path, statePath)
+}
+
+// DriftContext is Drift with a context for cancellation
+func (s *Scanner) DriftContext(ctx context.Context, path, statePath string) (*Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat path: %w", err)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		if paths, err = findTerraformFiles(ctx, path, s.exclude); err != nil {
+			return nil, err
+		}
+	}
+	s.report(ProgressDiscovered, path, len(paths))
+
+	config, err := parseTerraformFiles(ctx, paths, s.parseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("parse terraform: %w", err)
+	}
+
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	state, err := ParseState(content, statePath)
+	if err != nil {
+		return nil, fmt.Errorf("parse state: %w", err)
+	}
+	s.report(ProgressParsed, path, len(paths)+1)
+
+	result := CompareState(config, state)
+	s.evaluator.MapFindings(result)
+	s.applyExceptions(result)
+	return result, nil
+}
+
+// findTerraformFiles walks a directory for Terraform files, skipping
+// provider caches and dependencies
+func findTerraformFiles(ctx context.Context, dirPath string, exclude []string) ([]string, error) {
+	var paths []string
 
 	// Walk the directory tree
 	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-
-		// Skip directories
-		if d.IsDir() {
-			return nil
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		// Only process .tf files
-		if !strings.HasSuffix(path, ".tf") {
+		// Skip provider caches, downloaded modules, Node dependencies and
+		// excluded directories
+		if d.IsDir() {
+			if path != dirPath && (skippedDirs[d.Name()] || excluded(exclude, dirPath, path)) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Skip .terraform directory
-		if strings.Contains(path, ".terraform") {
+		// Only process Terraform files
+		if !isTerraformFile(path) || excluded(exclude, dirPath, path) {
 			return nil
 		}
 
-		// Read file
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("read %s: %w", path, err)
-		}
-
-		// Append content with a newline separator
-		allContent = append(allContent, content...)
-		allContent = append(allContent, []byte("\n\n")...)
-		fileCount++
+		paths = append(paths, path)
 
 		return nil
 	})
@@ -110,31 +334,80 @@ func (s *Scanner) ScanDirectory(dirPath string) (*Result, error) {
 		return nil, fmt.Errorf("walk directory: %w", err)
 	}
 
-	if fileCount == 0 {
-		return nil, fmt.Errorf("no .tf files found in %s", dirPath)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .tf or .tf.json files found in %s", dirPath)
 	}
 
-	fmt.Printf("📁 Scanning %d Terraform files in %s\n\n", fileCount, dirPath)
-
-	// Scan all content together
-	return s.Scan(allContent)
+	return paths, nil
 }
 
-// ScanFiles scans multiple specific files
-func (s *Scanner) ScanFiles(paths []string) (*Result, error) {
-	var allContent []byte
+// excluded reports whether path, found walking root, matches one of the
+// exclude patterns; see Options.Exclude
+func excluded(patterns []string, root, path string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
 
-	for _, path := range paths {
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("read %s: %w", path, err)
-		}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	rel = filepath.ToSlash(rel)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	abs = filepath.ToSlash(abs)
 
-		allContent = append(allContent, content...)
-		allContent = append(allContent, []byte("\n\n")...)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		switch {
+		case !strings.Contains(pattern, "/"):
+			if ok, _ := pathpkg.Match(pattern, filepath.Base(path)); ok {
+				return true
+			}
+		case pathpkg.IsAbs(pattern) || filepath.IsAbs(pattern):
+			if matchPath(pattern, abs) {
+				return true
+			}
+		default:
+			if matchPath(pattern, rel) {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	fmt.Printf("📁 Scanning %d Terraform files\n\n", len(paths))
+// matchPath matches a slash-separated path against a pattern whose
+// segments are matched with path.Match, and where a ** segment matches any
+// number of directories
+func matchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := pathpkg.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
 
-	return s.Scan(allContent)
+// isTerraformFile reports whether path is a Terraform configuration file
+func isTerraformFile(path string) bool {
+	return strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json")
 }
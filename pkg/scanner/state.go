@@ -0,0 +1,247 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// tfState is the subset of a Terraform state file (format version 4) used by
+// the scanner
+type tfState struct {
+	Version          int                      `json:"version"`
+	TerraformVersion string                   `json:"terraform_version"`
+	Outputs          map[string]tfStateOutput `json:"outputs"`
+	Resources        []tfStateResource        `json:"resources"`
+}
+
+type tfStateOutput struct {
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+type tfStateResource struct {
+	Module    string            `json:"module"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Instances []tfStateInstance `json:"instances"`
+}
+
+type tfStateInstance struct {
+	IndexKey            interface{}            `json:"index_key"`
+	Attributes          map[string]interface{} `json:"attributes"`
+	SensitiveAttributes [][]tfStatePathStep    `json:"sensitive_attributes"`
+	Dependencies        []string               `json:"dependencies"`
+}
+
+// tfStatePathStep is a step of an attribute path: an attribute name, or an
+// index into a list or map
+type tfStatePathStep struct {
+	Type  string      `json:"type"` // get_attr or index
+	Value interface{} `json:"value"`
+}
+
+// stateReferenceAttributes are the attributes other resources use to refer
+// to a resource, e.g. bucket = aws_s3_bucket.logs.id
+var stateReferenceAttributes = []string{"id", "arn"}
+
+// ParseState converts a Terraform state file (terraform.tfstate, format
+// version 4) into TerraformData. State holds what is actually deployed, with
+// every value resolved, including provider defaults. filename is recorded as
+// the source of every resource.
+func ParseState(content []byte, filename string) (*TerraformData, error) {
+	var state tfState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("decode state: %w", err)
+	}
+
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported state format version %d (expected 4)", state.Version)
+	}
+
+	data := newTerraformData()
+
+	// Index the values other resources refer to before converting, so
+	// references can be restored regardless of the order of resources
+	refs := make(map[string]map[string]string) // config address -> value -> reference
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		configAddress := stateConfigAddress(r)
+		for _, inst := range r.Instances {
+			address := configAddress + stateIndexKey(inst.IndexKey)
+			for _, attr := range stateReferenceAttributes {
+				value, ok := inst.Attributes[attr].(string)
+				if !ok || value == "" {
+					continue
+				}
+				if refs[configAddress] == nil {
+					refs[configAddress] = make(map[string]string)
+				}
+				refs[configAddress][value] = address + "." + attr
+			}
+		}
+	}
+
+	for _, r := range state.Resources {
+		if r.Mode != "managed" && r.Mode != "data" {
+			continue
+		}
+
+		configAddress := stateConfigAddress(r)
+		for _, inst := range r.Instances {
+			// Never carry sensitive values, such as passwords, into policy input
+			for _, path := range inst.SensitiveAttributes {
+				redactStatePath(inst.Attributes, path)
+			}
+			config := normalizePlanValues(inst.Attributes)
+			restoreStateReferences(config, stateDependencyRefs(configAddress, inst.Dependencies, refs))
+
+			resource := Resource{
+				Type:    r.Type,
+				Name:    r.Name,
+				Address: configAddress + stateIndexKey(inst.IndexKey),
+				Index:   planIndex(inst.IndexKey),
+				Config:  config,
+				File:    filename,
+			}
+			if r.Mode == "data" {
+				data.DataSources = append(data.DataSources, resource)
+			} else {
+				data.Resources = append(data.Resources, resource)
+			}
+		}
+	}
+
+	for name, output := range state.Outputs {
+		out := Output{
+			Name:      name,
+			Sensitive: output.Sensitive,
+		}
+		// Never carry the values of sensitive outputs into policy input
+		if !output.Sensitive {
+			out.Value = valueString(output.Value)
+		}
+		data.Outputs[name] = out
+	}
+
+	return data, nil
+}
+
+// redactStatePath replaces the value at an attribute path from
+// sensitive_attributes with redactedValue
+func redactStatePath(value interface{}, path []tfStatePathStep) interface{} {
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return redactedValue
+	}
+
+	step := path[0]
+	key := step.Value
+	if step.Type == "index" {
+		// Index values are typed, e.g. {"value": 0, "type": "number"}
+		if typed, ok := key.(map[string]interface{}); ok {
+			key = typed["value"]
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if name, ok := key.(string); ok {
+			if item, ok := v[name]; ok {
+				v[name] = redactStatePath(item, path[1:])
+			}
+		}
+	case []interface{}:
+		if i, ok := key.(float64); ok && i >= 0 && int(i) < len(v) {
+			v[int(i)] = redactStatePath(v[int(i)], path[1:])
+		}
+	}
+	return value
+}
+
+// stateConfigAddress returns the address of a state resource without its
+// instance key, e.g. module.logs.aws_s3_bucket.this
+func stateConfigAddress(r tfStateResource) string {
+	address := fmt.Sprintf("%s.%s", r.Type, r.Name)
+	if r.Mode == "data" {
+		address = "data." + address
+	}
+	return joinAddress(r.Module, address)
+}
+
+// stateDependencyRefs collects the referable values of the resources an
+// instance depends on. State written before dependencies were recorded
+// falls back to every resource but the instance's own.
+func stateDependencyRefs(self string, dependencies []string, refs map[string]map[string]string) map[string]string {
+	result := make(map[string]string)
+	if len(dependencies) > 0 {
+		for _, dep := range dependencies {
+			for value, ref := range refs[dep] {
+				result[value] = ref
+			}
+		}
+		return result
+	}
+
+	for address, values := range refs {
+		if address == self {
+			continue
+		}
+		for value, ref := range values {
+			result[value] = ref
+		}
+	}
+	return result
+}
+
+// restoreStateReferences replaces string values that hold another
+// resource's id or ARN with the reference itself (e.g.
+// "aws_s3_bucket.logs.id"), matching the HCL parser so the same policies
+// can pair resources up. A resource's own id and arn are left alone.
+func restoreStateReferences(config map[string]interface{}, refs map[string]string) {
+	if len(refs) == 0 {
+		return
+	}
+	for key, value := range config {
+		if key == "id" || key == "arn" {
+			continue
+		}
+		config[key] = restoreStateReference(value, refs)
+	}
+}
+
+func restoreStateReference(value interface{}, refs map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		if ref, ok := refs[v]; ok {
+			return ref
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = restoreStateReference(item, refs)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = restoreStateReference(item, refs)
+		}
+	}
+	return value
+}
+
+// stateIndexKey formats a decoded instance key as it appears in addresses
+func stateIndexKey(key interface{}) string {
+	switch k := key.(type) {
+	case float64:
+		return fmt.Sprintf("[%d]", int(k))
+	case string:
+		return fmt.Sprintf("[%q]", k)
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStateRedactsSensitiveAttributes(t *testing.T) {
+	content := `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "resources": [{
+    "mode": "managed",
+    "type": "aws_db_instance",
+    "name": "main",
+    "instances": [{
+      "attributes": {
+        "id": "db-1",
+        "password": "hunter2",
+        "tags": {"Owner": "data-team", "Secret": "s3cr3t"},
+        "ingress": [{"cidr": "10.0.0.0/8"}, {"cidr": "0.0.0.0/0"}]
+      },
+      "sensitive_attributes": [
+        [{"type": "get_attr", "value": "password"}],
+        [{"type": "get_attr", "value": "tags"}, {"type": "index", "value": {"value": "Secret", "type": "string"}}],
+        [{"type": "get_attr", "value": "ingress"}, {"type": "index", "value": {"value": 1, "type": "number"}}, {"type": "get_attr", "value": "cidr"}],
+        [{"type": "get_attr", "value": "missing"}]
+      ]
+    }]
+  }]
+}`
+
+	data, err := ParseState([]byte(content), "terraform.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(data.Resources))
+	}
+
+	want := map[string]interface{}{
+		"id":       "db-1",
+		"password": redactedValue,
+		"tags":     map[string]interface{}{"Owner": "data-team", "Secret": redactedValue},
+		"ingress": []interface{}{
+			map[string]interface{}{"cidr": "10.0.0.0/8"},
+			map[string]interface{}{"cidr": redactedValue},
+		},
+	}
+	if got := data.Resources[0].Config; !reflect.DeepEqual(got, want) {
+		t.Errorf("config = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// suppressionDirective starts a comment suppressing the findings of a block:
+//
+//	# kiln:ignore CC8.1 reason="legacy bucket" expires=2027-01-01
+//	resource "aws_s3_bucket" "legacy" {
+const suppressionDirective = "kiln:ignore"
+
+// Suppression is an accepted risk documented by a kiln:ignore comment on or
+// directly above a block. It suppresses the block's findings of the checks
+// or controls it names until it expires.
+type Suppression struct {
+	Targets []string `json:"targets"`           // check IDs or controls, e.g. KILN-S3-001 or CC8.1
+	Reason  string   `json:"reason"`            // why the risk is accepted
+	Expires string   `json:"expires,omitempty"` // date the suppression stops applying, e.g. 2027-01-01
+	File    string   `json:"file,omitempty"`
+	Line    int      `json:"line,omitempty"` // line of the comment
+
+	// Expired is set on the findings of an expired suppression, which are
+	// reported again
+	Expired bool `json:"expired,omitempty"`
+
+	blockLine int // first line of the block the comment applies to
+}
+
+// ExpiredOn reports whether the suppression no longer applies on day
+func (s Suppression) ExpiredOn(day time.Time) bool {
+	return s.Expires != "" && day.Format(time.DateOnly) >= s.Expires
+}
+
+// matches reports whether the suppression covers a finding: one about the
+// block below the comment, of a check or control it names
+func (s Suppression) matches(f Finding) bool {
+	if f.File != s.File || f.StartLine != s.blockLine || f.StartLine == 0 {
+		return false
+	}
+
+	for _, target := range s.Targets {
+		if strings.EqualFold(target, f.CheckID) {
+			return true
+		}
+		for _, control := range strings.Split(f.Control, ", ") {
+			if strings.EqualFold(target, control) {
+				return true
+			}
+		}
+		for _, ref := range f.Controls {
+			if strings.EqualFold(target, ref.Control) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applySuppressions moves the violations and warnings covered by a
+// suppression to result.Suppressed. Findings of an expired suppression stay
+// where they are, with the suppression attached so reports can say so.
+func applySuppressions(result *Result, suppressions []Suppression, now time.Time) {
+	if result.Suppressed == nil {
+		result.Suppressed = []Finding{}
+	}
+	if len(suppressions) == 0 {
+		return
+	}
+
+	for _, findings := range []*[]Finding{&result.Violations, &result.Warnings} {
+		active := (*findings)[:0]
+		for _, f := range *findings {
+			for _, s := range suppressions {
+				if s.matches(f) {
+					s.Expired = s.ExpiredOn(now)
+					f.Suppression = &s
+					break
+				}
+			}
+
+			if f.Suppression != nil && !f.Suppression.Expired {
+				result.Suppressed = append(result.Suppressed, f)
+				continue
+			}
+			active = append(active, f)
+		}
+		*findings = active
+	}
+}
+
+// parseSuppressions finds the kiln:ignore comments of a native syntax
+// configuration file. Each applies to the block starting on its own line,
+// when it trails the block header, or on the line right below it.
+// Malformed comments are returned as errors.
+func parseSuppressions(content []byte, path string) ([]Suppression, []ScanError) {
+	tokens, diags := hclsyntax.LexConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, nil
+	}
+
+	var suppressions []Suppression
+	var errs []ScanError
+	for i, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		s, ok, err := parseSuppression(string(token.Bytes))
+		if !ok {
+			continue
+		}
+
+		line := token.Range.Start.Line
+		if err != nil {
+			errs = append(errs, ScanError{
+				File:    path,
+				Line:    line,
+				Column:  token.Range.Start.Column,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		s.File = path
+		s.Line = line
+		s.blockLine = suppressedLine(tokens, i)
+		if s.blockLine == 0 {
+			errs = append(errs, ScanError{
+				File:    path,
+				Line:    line,
+				Column:  token.Range.Start.Column,
+				Message: suppressionDirective + " must be on or directly above the block it suppresses",
+			})
+			continue
+		}
+		suppressions = append(suppressions, s)
+	}
+
+	return suppressions, errs
+}
+
+// suppressedLine returns the line a comment at tokens[i] applies to: its own
+// line when it trails code, otherwise the line of the code right below it
+// and any comments that follow. It is 0 when a blank line or the end of the
+// file comes first.
+func suppressedLine(tokens hclsyntax.Tokens, i int) int {
+	comment := tokens[i]
+	if i > 0 {
+		prev := tokens[i-1]
+		if prev.Range.End.Line == comment.Range.Start.Line &&
+			prev.Type != hclsyntax.TokenNewline && prev.Type != hclsyntax.TokenComment {
+			return comment.Range.Start.Line
+		}
+	}
+
+	last := commentEndLine(comment)
+	for _, token := range tokens[i+1:] {
+		switch token.Type {
+		case hclsyntax.TokenComment:
+			if token.Range.Start.Line > last+1 {
+				return 0
+			}
+			last = commentEndLine(token)
+		case hclsyntax.TokenNewline:
+			// The end of a /* */ comment's line, or a blank line
+			if token.Range.Start.Line > last {
+				return 0
+			}
+		case hclsyntax.TokenEOF:
+			return 0
+		default:
+			if token.Range.Start.Line > last+1 {
+				return 0
+			}
+			return token.Range.Start.Line
+		}
+	}
+	return 0
+}
+
+// commentEndLine is the last line a comment's text is on; # and // comments
+// include the newline ending them
+func commentEndLine(comment hclsyntax.Token) int {
+	text := strings.TrimSuffix(string(comment.Bytes), "\n")
+	return comment.Range.Start.Line + strings.Count(text, "\n")
+}
+
+// parseSuppression parses the text of a comment. ok is false for comments
+// that aren't kiln:ignore directives.
+func parseSuppression(comment string) (s Suppression, ok bool, err error) {
+	text := strings.TrimSpace(comment)
+	switch {
+	case strings.HasPrefix(text, "#"):
+		text = text[1:]
+	case strings.HasPrefix(text, "//"):
+		text = text[2:]
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(text[2:], "*/")
+	}
+	// The directive ends with the comment's first line
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	text = strings.TrimSpace(text)
+
+	rest, found := strings.CutPrefix(text, suppressionDirective)
+	if !found || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return s, false, nil
+	}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		end := strings.IndexAny(rest, " \t=")
+		if end < 0 || rest[end] != '=' {
+			if end < 0 {
+				end = len(rest)
+			}
+			for _, target := range strings.Split(rest[:end], ",") {
+				if target != "" {
+					s.Targets = append(s.Targets, target)
+				}
+			}
+			rest = rest[end:]
+			continue
+		}
+
+		key := rest[:end]
+		rest = rest[end+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return s, true, fmt.Errorf("%s: %s has an unterminated quoted value", suppressionDirective, key)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+
+		switch key {
+		case "reason":
+			s.Reason = strings.TrimSpace(value)
+		case "expires":
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				return s, true, fmt.Errorf("%s: expires must be a date like 2027-01-01, got %q", suppressionDirective, value)
+			}
+			s.Expires = value
+		default:
+			return s, true, fmt.Errorf("%s: unknown option %q (expected reason or expires)", suppressionDirective, key)
+		}
+	}
+
+	if len(s.Targets) == 0 {
+		return s, true, fmt.Errorf("%s needs the check IDs or controls to suppress, e.g. %s KILN-S3-001 reason=\"...\"", suppressionDirective, suppressionDirective)
+	}
+	if s.Reason == "" {
+		return s, true, fmt.Errorf("%s %s needs a reason=\"...\" justifying it", suppressionDirective, strings.Join(s.Targets, ","))
+	}
+
+	return s, true, nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 Kiln
+// Licensed under the Apache License, Version 2.0
+
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSuppression(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    Suppression
+		wantOK  bool
+		wantErr string
+	}{
+		{
+			name:    "not a directive",
+			comment: "# encrypted with the default key",
+		},
+		{
+			name:    "directive prefix of another word",
+			comment: "# kiln:ignored",
+		},
+		{
+			name:    "check and reason",
+			comment: `# kiln:ignore KILN-S3-001 reason="legacy bucket"`,
+			want:    Suppression{Targets: []string{"KILN-S3-001"}, Reason: "legacy bucket"},
+			wantOK:  true,
+		},
+		{
+			name:    "several targets and expiry",
+			comment: `// kiln:ignore KILN-S3-001,CC8.1 reason=archive expires=2027-01-01`,
+			want:    Suppression{Targets: []string{"KILN-S3-001", "CC8.1"}, Reason: "archive", Expires: "2027-01-01"},
+			wantOK:  true,
+		},
+		{
+			name:    "block comment",
+			comment: "/* kiln:ignore CC6.1 reason=\"vpn only\"\n   more text */",
+			want:    Suppression{Targets: []string{"CC6.1"}, Reason: "vpn only"},
+			wantOK:  true,
+		},
+		{
+			name:    "no targets",
+			comment: `# kiln:ignore reason="legacy bucket"`,
+			wantOK:  true,
+			wantErr: "needs the check IDs or controls",
+		},
+		{
+			name:    "no reason",
+			comment: `# kiln:ignore KILN-S3-001`,
+			wantOK:  true,
+			wantErr: `needs a reason="..."`,
+		},
+		{
+			name:    "invalid expiry",
+			comment: `# kiln:ignore KILN-S3-001 reason=x expires=next-year`,
+			wantOK:  true,
+			wantErr: `expires must be a date like 2027-01-01, got "next-year"`,
+		},
+		{
+			name:    "unknown option",
+			comment: `# kiln:ignore KILN-S3-001 reason=x until=2027-01-01`,
+			wantOK:  true,
+			wantErr: `unknown option "until"`,
+		},
+		{
+			name:    "unterminated quote",
+			comment: `# kiln:ignore KILN-S3-001 reason="legacy`,
+			wantOK:  true,
+			wantErr: "unterminated quoted value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseSuppression(tt.comment)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suppression = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLines []int // block line of each suppression
+		wantErr   string
+	}{
+		{
+			name: "above the block",
+			content: `# kiln:ignore KILN-S3-001 reason=x
+resource "aws_s3_bucket" "a" {
+}`,
+			wantLines: []int{2},
+		},
+		{
+			name: "trailing the block header",
+			content: `resource "aws_s3_bucket" "a" { # kiln:ignore KILN-S3-001 reason=x
+}`,
+			wantLines: []int{1},
+		},
+		{
+			name: "above other comments",
+			content: `# kiln:ignore KILN-S3-001 reason=x
+# Legacy bucket
+resource "aws_s3_bucket" "a" {
+}`,
+			wantLines: []int{3},
+		},
+		{
+			name: "separated by a blank line",
+			content: `# kiln:ignore KILN-S3-001 reason=x
+
+resource "aws_s3_bucket" "a" {
+}`,
+			wantErr: "must be on or directly above the block",
+		},
+		{
+			name: "at the end of the file",
+			content: `resource "aws_s3_bucket" "a" {
+}
+# kiln:ignore KILN-S3-001 reason=x
+`,
+			wantErr: "must be on or directly above the block",
+		},
+		{
+			name: "malformed",
+			content: `# kiln:ignore KILN-S3-001
+resource "aws_s3_bucket" "a" {
+}`,
+			wantErr: "needs a reason",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sups, errs := parseSuppressions([]byte(tt.content), "main.tf")
+
+			var lines []int
+			for _, s := range sups {
+				lines = append(lines, s.blockLine)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("block lines = %v, want %v", lines, tt.wantLines)
+			}
+
+			messages := errorMessages(errs)
+			if tt.wantErr == "" && messages != "" {
+				t.Errorf("unexpected errors: %s", messages)
+			}
+			if !strings.Contains(messages, tt.wantErr) {
+				t.Errorf("errors %q don't mention %q", messages, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplySuppressions(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	finding := Finding{
+		CheckID:   "KILN-S3-001",
+		Control:   "CC6.1, CC6.7",
+		Resource:  "aws_s3_bucket.a",
+		File:      "main.tf",
+		StartLine: 2,
+	}
+
+	tests := []struct {
+		name           string
+		suppression    Suppression
+		wantSuppressed bool
+		wantExpired    bool
+	}{
+		{
+			name:           "by check",
+			suppression:    Suppression{Targets: []string{"kiln-s3-001"}, File: "main.tf", blockLine: 2},
+			wantSuppressed: true,
+		},
+		{
+			name:           "by control",
+			suppression:    Suppression{Targets: []string{"CC6.7"}, File: "main.tf", blockLine: 2},
+			wantSuppressed: true,
+		},
+		{
+			name:        "other check",
+			suppression: Suppression{Targets: []string{"KILN-S3-002"}, File: "main.tf", blockLine: 2},
+		},
+		{
+			name:        "other block",
+			suppression: Suppression{Targets: []string{"KILN-S3-001"}, File: "main.tf", blockLine: 10},
+		},
+		{
+			name:        "other file",
+			suppression: Suppression{Targets: []string{"KILN-S3-001"}, File: "other.tf", blockLine: 2},
+		},
+		{
+			name:           "not yet expired",
+			suppression:    Suppression{Targets: []string{"KILN-S3-001"}, Expires: "2026-06-02", File: "main.tf", blockLine: 2},
+			wantSuppressed: true,
+		},
+		{
+			name:        "expired",
+			suppression: Suppression{Targets: []string{"KILN-S3-001"}, Expires: "2026-06-01", File: "main.tf", blockLine: 2},
+			wantExpired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Violations: []Finding{finding}}
+			applySuppressions(result, []Suppression{tt.suppression}, now)
+
+			if got := len(result.Suppressed) == 1; got != tt.wantSuppressed {
+				t.Fatalf("suppressed = %v, want %v", got, tt.wantSuppressed)
+			}
+			if len(result.Suppressed)+len(result.Violations) != 1 {
+				t.Fatalf("finding lost or duplicated: %d violations, %d suppressed", len(result.Violations), len(result.Suppressed))
+			}
+			if tt.wantExpired {
+				s := result.Violations[0].Suppression
+				if s == nil || !s.Expired {
+					t.Errorf("expected the expired suppression on the reported finding, got %+v", s)
+				}
+			}
+		})
+	}
+}
@@ -3,29 +3,202 @@
 
 package scanner
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Result represents the output of a compliance scan
 type Result struct {
-	Score      int       `json:"score"`
-	Violations []Finding `json:"violations"`
-	Warnings   []Finding `json:"warnings"`
-	Passed     []Finding `json:"passed"`
-	ScannedAt  string    `json:"scanned_at"`
+	Score      int         `json:"score"`
+	Violations []Finding   `json:"violations"`
+	Warnings   []Finding   `json:"warnings"`
+	Passed     []Finding   `json:"passed"`
+	Suppressed []Finding   `json:"suppressed"` // violations and warnings accepted by a kiln:ignore comment or exception
+	Errors     []ScanError `json:"errors"`
+	ScannedAt  string      `json:"scanned_at"`
+
+	// Frameworks scores each framework on the findings mapped to its
+	// controls, and each custom policy package on its own findings
+	Frameworks []FrameworkScore `json:"frameworks,omitempty"`
+
+	// Exceptions are the entries of the exceptions file applied to the
+	// scan, with what each matched
+	Exceptions []ExceptionStatus `json:"exceptions,omitempty"`
+	// Baselined are the violations and warnings recorded in the baseline
+	// scanned against, which are still scored but don't fail the scan.
+	// BaselineResolved counts the baseline's findings that are gone.
+	Baselined        []Finding `json:"baselined,omitempty"`
+	BaselineResolved int       `json:"baseline_resolved,omitempty"`
+}
+
+// FrameworkScore summarizes the findings of one framework
+type FrameworkScore struct {
+	Framework  string `json:"framework"` // framework ID, or the package of custom policies
+	Name       string `json:"name"`
+	Score      int    `json:"score"`
+	Violations int    `json:"violations"`
+	Warnings   int    `json:"warnings"`
+	Passed     int    `json:"passed"`
 }
 
 // Finding represents a single compliance check result
 type Finding struct {
+	CheckID     string `json:"check_id,omitempty"`    // stable ID of the check, e.g. KILN-S3-001
+	Fingerprint string `json:"fingerprint,omitempty"` // stable ID of the finding; see ComputeFingerprint
+	Title       string `json:"title,omitempty"`       // title of the check
 	Control     string `json:"control"`
 	Severity    string `json:"severity"`
 	Resource    string `json:"resource"`
 	Message     string `json:"message"`
 	Remediation string `json:"remediation,omitempty"`
+	File        string `json:"file,omitempty"`
+	StartLine   int    `json:"start_line,omitempty"`
+	StartColumn int    `json:"start_column,omitempty"`
+	EndLine     int    `json:"end_line,omitempty"`
+	Package     string `json:"package,omitempty"`   // Rego package that produced the finding, e.g. checks
+	Framework   string `json:"framework,omitempty"` // framework Control belongs to, e.g. cis-aws
+
+	// Controls are every control the check is mapped to in the crosswalk,
+	// across all frameworks
+	Controls []ControlRef `json:"controls,omitempty"`
+
+	// References are documentation URLs for the check
+	References []string `json:"references,omitempty"`
+
+	// Suppression is the kiln:ignore comment covering the finding, if any
+	Suppression *Suppression `json:"suppression,omitempty"`
+
+	// Exception is the entry of the exceptions file covering the finding,
+	// if any
+	Exception *Exception `json:"exception,omitempty"`
+}
+
+// Location formats the finding's source position as file:line:column
+func (f Finding) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	if f.StartLine == 0 {
+		return f.File
+	}
+	return fmt.Sprintf("%s:%d:%d", f.File, f.StartLine, f.StartColumn)
+}
+
+// ScanError is a problem, such as a syntax error, that kept a file, block or
+// module from being scanned. The rest of the configuration is still scanned.
+type ScanError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Location formats the error's source position as file:line:column
+func (e ScanError) Location() string {
+	if e.File == "" {
+		return ""
+	}
+	if e.Line == 0 {
+		return e.File
+	}
+	return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
 }
 
 // TerraformData represents parsed Terraform configuration
 type TerraformData struct {
-	Resources []Resource          `json:"resources"`
-	Variables map[string]Variable `json:"variables"`
-	Outputs   map[string]Output   `json:"outputs"`
+	Resources         []Resource                  `json:"resources"`
+	DataSources       []Resource                  `json:"data_sources"`
+	Providers         []Provider                  `json:"providers"`
+	Backends          []Backend                   `json:"backends"`
+	RequiredProviders map[string]RequiredProvider `json:"required_providers"`
+	Variables         map[string]Variable         `json:"variables"`
+	Locals            map[string]interface{}      `json:"locals"`
+	Outputs           map[string]Output           `json:"outputs"`
+
+	// Errors are the files and modules that could not be parsed
+	Errors []ScanError `json:"-"`
+
+	// Suppressions are the kiln:ignore comments of the parsed files
+	Suppressions []Suppression `json:"-"`
+}
+
+func newTerraformData() *TerraformData {
+	return &TerraformData{
+		Resources:         []Resource{},
+		DataSources:       []Resource{},
+		Providers:         []Provider{},
+		Backends:          []Backend{},
+		RequiredProviders: make(map[string]RequiredProvider),
+		Variables:         make(map[string]Variable),
+		Locals:            make(map[string]interface{}),
+		Outputs:           make(map[string]Output),
+	}
+}
+
+// Merge adds the contents of other to d
+func (d *TerraformData) Merge(other *TerraformData) {
+	d.Resources = append(d.Resources, other.Resources...)
+	d.DataSources = append(d.DataSources, other.DataSources...)
+	d.Providers = append(d.Providers, other.Providers...)
+	d.Backends = append(d.Backends, other.Backends...)
+	for name, p := range other.RequiredProviders {
+		d.RequiredProviders[name] = p
+	}
+	for name, v := range other.Variables {
+		d.Variables[name] = v
+	}
+	for name, l := range other.Locals {
+		d.Locals[name] = l
+	}
+	for name, o := range other.Outputs {
+		d.Outputs[name] = o
+	}
+	d.Errors = append(d.Errors, other.Errors...)
+	d.Suppressions = append(d.Suppressions, other.Suppressions...)
+}
+
+// FindResource returns the resource or data source with the given address, if any
+func (d *TerraformData) FindResource(address string) *Resource {
+	for i := range d.Resources {
+		if d.Resources[i].Address == address {
+			return &d.Resources[i]
+		}
+	}
+	for i := range d.DataSources {
+		if d.DataSources[i].Address == address {
+			return &d.DataSources[i]
+		}
+	}
+	return nil
+}
+
+// Locate returns where the object a finding refers to is declared: a
+// resource or data source address, var.<name>, output.<name> or
+// terraform.backend.<type>
+func (d *TerraformData) Locate(address string) (string, *SourceRange) {
+	if resource := d.FindResource(address); resource != nil {
+		return resource.File, resource.Range
+	}
+
+	switch {
+	case strings.HasPrefix(address, "var."):
+		if v, ok := d.Variables[strings.TrimPrefix(address, "var.")]; ok {
+			return v.File, v.Range
+		}
+	case strings.HasPrefix(address, "output."):
+		if o, ok := d.Outputs[strings.TrimPrefix(address, "output.")]; ok {
+			return o.File, o.Range
+		}
+	case strings.HasPrefix(address, "terraform.backend."):
+		for _, backend := range d.Backends {
+			if address == "terraform.backend."+backend.Type {
+				return backend.File, nil
+			}
+		}
+	}
+
+	return "", nil
 }
 
 // Resource represents a Terraform resource
@@ -33,20 +206,60 @@ type Resource struct {
 	Type    string                 `json:"type"`
 	Name    string                 `json:"name"`
 	Address string                 `json:"address"`
+	Index   interface{}            `json:"index,omitempty"`
 	Config  map[string]interface{} `json:"config"`
+	File    string                 `json:"file,omitempty"`
+	Range   *SourceRange           `json:"range,omitempty"`
+}
+
+// SourceRange is the span of a block within its source file
+type SourceRange struct {
+	StartLine   int `json:"start_line"`
+	StartColumn int `json:"start_column"`
+	EndLine     int `json:"end_line"`
+	EndColumn   int `json:"end_column"`
+}
+
+// Provider represents a provider configuration block
+type Provider struct {
+	Name   string                 `json:"name"`
+	Alias  string                 `json:"alias,omitempty"`
+	Module string                 `json:"module,omitempty"`
+	Config map[string]interface{} `json:"config"`
+	File   string                 `json:"file,omitempty"`
+}
+
+// Backend represents the state backend of a root module (terraform { backend "s3" {} })
+type Backend struct {
+	Type   string                 `json:"type"`
+	Config map[string]interface{} `json:"config"`
+	File   string                 `json:"file,omitempty"`
+}
+
+// RequiredProvider is an entry in terraform { required_providers {} }
+type RequiredProvider struct {
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
 }
 
 // Variable represents a Terraform variable
 type Variable struct {
-	Name        string      `json:"name"`
-	Type        string      `json:"type"`
-	Default     interface{} `json:"default,omitempty"`
-	Description string      `json:"description,omitempty"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Default     interface{}  `json:"default,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Sensitive   bool         `json:"sensitive"`
+	File        string       `json:"file,omitempty"`
+	Range       *SourceRange `json:"range,omitempty"`
 }
 
 // Output represents a Terraform output
 type Output struct {
-	Name        string `json:"name"`
-	Value       string `json:"value"`
-	Description string `json:"description,omitempty"`
+	Name        string       `json:"name"`
+	Value       string       `json:"value"`
+	Expression  string       `json:"expression,omitempty"`
+	Sensitive   bool         `json:"sensitive"`
+	Description string       `json:"description,omitempty"`
+	File        string       `json:"file,omitempty"`
+	Range       *SourceRange `json:"range,omitempty"`
 }
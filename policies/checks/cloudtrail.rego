@@ -0,0 +1,183 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# CloudTrail
+
+# METADATA
+# title: CloudTrail configured
+# description: CloudTrail must be enabled
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-create-and-update-a-trail.html
+# custom:
+#   check_id: KILN-CT-001
+#   severity: critical
+#   remediation: Add aws_cloudtrail resource with enable_logging = true
+#   resource_types: [aws_cloudtrail]
+#   controls:
+#     soc2: [CC7.2]
+#     iso27001: [A.8.15]
+#     hipaa: [164.312(b)]
+#     pci: [10.2.1]
+#     cis-aws: ["3.1"]
+violations[finding] {
+    count([r | r := input.resources[_]; r.type == "aws_cloudtrail"]) == 0
+
+    finding := {
+        "check_id": "KILN-CT-001",
+        "resource": "infrastructure",
+        "message": "No CloudTrail configured for API logging"
+    }
+}
+
+# METADATA
+# title: CloudTrail logging enabled
+# description: CloudTrail must have logging enabled
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-create-and-update-a-trail.html
+# custom:
+#   check_id: KILN-CT-002
+#   severity: critical
+#   remediation: Set enable_logging = true
+#   resource_types: [aws_cloudtrail]
+#   controls:
+#     soc2: [CC7.2]
+#     iso27001: [A.8.15]
+#     hipaa: [164.312(b)]
+#     pci: [10.2.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    not resource.config.enable_logging == true
+
+    finding := {
+        "check_id": "KILN-CT-002",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' has logging disabled", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    resource.config.enable_logging == true
+
+    finding := {
+        "check_id": "KILN-CT-002",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' has logging enabled", [resource.name])
+    }
+}
+
+# METADATA
+# title: CloudTrail multi-region
+# description: CloudTrail should be multi-region
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/receive-cloudtrail-log-files-from-multiple-regions.html
+# custom:
+#   check_id: KILN-CT-003
+#   severity: medium
+#   remediation: Set is_multi_region_trail = true
+#   resource_types: [aws_cloudtrail]
+#   controls:
+#     soc2: [CC7.2]
+#     cis-aws: ["3.1"]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    not resource.config.is_multi_region_trail == true
+
+    finding := {
+        "check_id": "KILN-CT-003",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' is not multi-region", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    resource.config.is_multi_region_trail == true
+
+    finding := {
+        "check_id": "KILN-CT-003",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' is multi-region", [resource.name])
+    }
+}
+
+# METADATA
+# title: CloudTrail log file validation
+# description: CloudTrail should protect its logs against tampering
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-log-file-validation-intro.html
+# custom:
+#   check_id: KILN-CT-004
+#   severity: medium
+#   remediation: Set enable_log_file_validation = true
+#   resource_types: [aws_cloudtrail]
+#   controls:
+#     iso27001: [A.8.15]
+#     hipaa: [164.312(c)(1)]
+#     pci: [10.3.4]
+#     cis-aws: ["3.2"]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    not resource.config.enable_log_file_validation == true
+
+    finding := {
+        "check_id": "KILN-CT-004",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' does not validate log file integrity", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    resource.config.enable_log_file_validation == true
+
+    finding := {
+        "check_id": "KILN-CT-004",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' validates log file integrity", [resource.name])
+    }
+}
+
+# METADATA
+# title: CloudTrail logs encrypted with KMS
+# description: CloudTrail logs should be encrypted with a KMS key
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/encrypting-cloudtrail-log-files-with-aws-kms.html
+# custom:
+#   check_id: KILN-CT-005
+#   severity: medium
+#   remediation: Set kms_key_id to a customer managed KMS key
+#   resource_types: [aws_cloudtrail]
+#   controls:
+#     cis-aws: ["3.5"]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    not resource.config.kms_key_id
+
+    finding := {
+        "check_id": "KILN-CT-005",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' logs are not encrypted with a KMS key", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_cloudtrail"
+    resource.config.kms_key_id
+
+    finding := {
+        "check_id": "KILN-CT-005",
+        "resource": resource.address,
+        "message": sprintf("CloudTrail '%s' logs are encrypted with a KMS key", [resource.name])
+    }
+}
@@ -0,0 +1,113 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# EC2 instances and EBS volumes
+
+# METADATA
+# title: EBS volume encryption at rest
+# description: EBS volumes must be encrypted at rest
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/ebs/latest/userguide/ebs-encryption.html
+# custom:
+#   check_id: KILN-EC2-001
+#   severity: critical
+#   remediation: Set 'encrypted = true' on the aws_ebs_volume resource
+#   resource_types: [aws_ebs_volume]
+#   controls:
+#     soc2: [CC6.6]
+#     iso27001: [A.8.24]
+#     hipaa: [164.312(a)(2)(iv)]
+#     pci: [3.5.1]
+#     cis-aws: [2.2.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_ebs_volume"
+    not resource.config.encrypted == true
+
+    finding := {
+        "check_id": "KILN-EC2-001",
+        "resource": resource.address,
+        "message": sprintf("EBS volume '%s' is not encrypted", [resource.name])
+    }
+}
+
+# METADATA
+# title: EBS encryption by default
+# description: EBS encryption by default must stay enabled
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/ebs/latest/userguide/ebs-encryption.html
+# custom:
+#   check_id: KILN-EC2-002
+#   severity: high
+#   remediation: Set enabled = true on aws_ebs_encryption_by_default
+#   resource_types: [aws_ebs_encryption_by_default]
+#   controls:
+#     cis-aws: [2.2.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_ebs_encryption_by_default"
+    resource.config.enabled == false
+
+    finding := {
+        "check_id": "KILN-EC2-002",
+        "resource": resource.address,
+        "message": "EBS encryption by default is disabled"
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_ebs_encryption_by_default"
+    not resource.config.enabled == false
+
+    finding := {
+        "check_id": "KILN-EC2-002",
+        "resource": resource.address,
+        "message": "EBS encryption by default is enabled"
+    }
+}
+
+# METADATA
+# title: EC2 instance metadata requires IMDSv2
+# description: Instances must require IMDSv2
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/configuring-instance-metadata-service.html
+# custom:
+#   check_id: KILN-EC2-003
+#   severity: medium
+#   remediation: Add metadata_options { http_tokens = "required" }
+#   resource_types: [aws_instance, aws_launch_template]
+#   controls:
+#     cis-aws: ["5.6"]
+violations[finding] {
+    resource := input.resources[_]
+    imds_types[resource.type]
+    not requires_imdsv2(resource)
+
+    finding := {
+        "check_id": "KILN-EC2-003",
+        "resource": resource.address,
+        "message": sprintf("%s '%s' allows IMDSv1", [resource.type, resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    imds_types[resource.type]
+    requires_imdsv2(resource)
+
+    finding := {
+        "check_id": "KILN-EC2-003",
+        "resource": resource.address,
+        "message": sprintf("%s '%s' requires IMDSv2", [resource.type, resource.name])
+    }
+}
+
+imds_types := {"aws_instance", "aws_launch_template"}
+
+# Helper: Check for IMDSv2 enforcement
+requires_imdsv2(resource) {
+    blocks(resource.config.metadata_options)[_].http_tokens == "required"
+}
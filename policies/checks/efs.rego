@@ -0,0 +1,42 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# EFS file systems
+
+# METADATA
+# title: EFS file system encryption at rest
+# description: EFS file systems must be encrypted at rest
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/efs/latest/ug/encryption.html
+# custom:
+#   check_id: KILN-EFS-001
+#   severity: high
+#   remediation: Set 'encrypted = true' on the aws_efs_file_system resource
+#   resource_types: [aws_efs_file_system]
+#   controls:
+#     cis-aws: [2.4.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_efs_file_system"
+    not resource.config.encrypted == true
+
+    finding := {
+        "check_id": "KILN-EFS-001",
+        "resource": resource.address,
+        "message": sprintf("EFS file system '%s' is not encrypted", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_efs_file_system"
+    resource.config.encrypted == true
+
+    finding := {
+        "check_id": "KILN-EFS-001",
+        "resource": resource.address,
+        "message": sprintf("EFS file system '%s' is encrypted", [resource.name])
+    }
+}
@@ -0,0 +1,80 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# Load balancers
+
+# METADATA
+# title: Load balancer listener encryption in transit
+# description: Load balancer listeners must use HTTPS/TLS
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/elasticloadbalancing/latest/application/create-https-listener.html
+# custom:
+#   check_id: KILN-ELB-001
+#   severity: critical
+#   remediation: Change protocol to HTTPS and add certificate_arn, or redirect HTTP to
+#     HTTPS
+#   resource_types: [aws_lb_listener, aws_alb_listener]
+#   controls:
+#     soc2: [CC6.7]
+#     iso27001: [A.8.24]
+#     hipaa: [164.312(e)(1)]
+#     pci: [4.2.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_lb_listener"
+    resource.config.protocol == "HTTP"
+    not redirects_to_https(resource)
+
+    finding := {
+        "check_id": "KILN-ELB-001",
+        "resource": resource.address,
+        "message": sprintf("Load balancer listener '%s' uses unencrypted HTTP", [resource.name])
+    }
+}
+
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_alb_listener"
+    resource.config.protocol == "HTTP"
+    not redirects_to_https(resource)
+
+    finding := {
+        "check_id": "KILN-ELB-001",
+        "resource": resource.address,
+        "message": sprintf("ALB listener '%s' uses unencrypted HTTP", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_lb_listener"
+    resource.config.protocol == "HTTPS"
+
+    finding := {
+        "check_id": "KILN-ELB-001",
+        "resource": resource.address,
+        "message": sprintf("Load balancer listener '%s' uses encrypted HTTPS", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_lb_listener"
+    resource.config.protocol == "HTTP"
+    redirects_to_https(resource)
+
+    finding := {
+        "check_id": "KILN-ELB-001",
+        "resource": resource.address,
+        "message": sprintf("Load balancer listener '%s' redirects HTTP to HTTPS", [resource.name])
+    }
+}
+
+# Helper: Check if HTTP listener is redirecting to HTTPS
+redirects_to_https(listener) {
+    action := blocks(listener.config.default_action)[_]
+    action.type == "redirect"
+    blocks(action.redirect)[_].protocol == "HTTPS"
+}
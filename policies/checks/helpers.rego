@@ -0,0 +1,51 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# Every check is written once and identified by a stable check_id, e.g.
+# KILN-S3-001. A METADATA annotation above one of its rules gives its title,
+# severity, remediation and the controls it provides evidence for in each
+# framework; findings carry only the check_id, resource and message.
+
+# Evaluate entry point - aggregates all findings
+evaluate = result {
+    result := {
+        "violations": violations,
+        "warnings": warnings,
+        "passed": passed
+    }
+}
+
+# Shared helpers for reading parsed Terraform configuration
+
+# Nested blocks appear as an object when declared once and as an array when
+# repeated. blocks() always returns an array so rules can iterate either form.
+blocks(value) = value {
+    is_array(value)
+}
+
+blocks(value) = [value] {
+    is_object(value)
+}
+
+# An attribute such as bucket or vpc_id refers to a resource
+references(ref, resource) {
+    # Reference from within a module: "module.x.aws_s3_bucket.example.id"
+    ref == sprintf("%s.id", [resource.address])
+}
+
+references(ref, resource) {
+    # Direct reference: "aws_s3_bucket.example.id"
+    ref == sprintf("%s.%s.id", [resource.type, resource.name])
+}
+
+references(ref, resource) {
+    # Resource address: "aws_s3_bucket.example"
+    ref == resource.address
+}
+
+references(ref, resource) {
+    # Just the name
+    ref == resource.name
+}
@@ -0,0 +1,100 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# IAM and KMS
+
+# METADATA
+# title: IAM policy least privilege
+# description: IAM policy documents must not grant every action
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/IAM/latest/UserGuide/best-practices.html
+# custom:
+#   check_id: KILN-IAM-001
+#   severity: high
+#   remediation: Grant only the specific actions required (least privilege)
+#   resource_types: [aws_iam_policy_document]
+#   controls:
+#     soc2: [CC6.1]
+#     iso27001: [A.5.15]
+#     hipaa: [164.312(a)(1)]
+violations[finding] {
+    doc := input.data_sources[_]
+    doc.type == "aws_iam_policy_document"
+    grants_all_actions(doc)
+
+    finding := {
+        "check_id": "KILN-IAM-001",
+        "resource": doc.address,
+        "message": sprintf("IAM policy document '%s' allows all actions (\"*\")", [doc.name])
+    }
+}
+
+passed[finding] {
+    doc := input.data_sources[_]
+    doc.type == "aws_iam_policy_document"
+    not grants_all_actions(doc)
+
+    finding := {
+        "check_id": "KILN-IAM-001",
+        "resource": doc.address,
+        "message": sprintf("IAM policy document '%s' grants specific actions", [doc.name])
+    }
+}
+
+# METADATA
+# title: KMS key rotation
+# description: Symmetric KMS keys should rotate automatically
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/kms/latest/developerguide/rotate-keys.html
+# custom:
+#   check_id: KILN-KMS-001
+#   severity: medium
+#   remediation: Set enable_key_rotation = true
+#   resource_types: [aws_kms_key]
+#   controls:
+#     iso27001: [A.8.24]
+#     pci: [3.7.4]
+#     cis-aws: ["3.6"]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_kms_key"
+    symmetric_key(resource)
+    not resource.config.enable_key_rotation == true
+
+    finding := {
+        "check_id": "KILN-KMS-001",
+        "resource": resource.address,
+        "message": sprintf("KMS key '%s' does not rotate automatically", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_kms_key"
+    symmetric_key(resource)
+    resource.config.enable_key_rotation == true
+
+    finding := {
+        "check_id": "KILN-KMS-001",
+        "resource": resource.address,
+        "message": sprintf("KMS key '%s' rotates automatically", [resource.name])
+    }
+}
+
+# Helper: Check for Allow statements granting "*"
+grants_all_actions(doc) {
+    statement := blocks(doc.config.statement)[_]
+    not statement.effect == "Deny"
+    statement.actions[_] == "*"
+}
+
+# Helper: Only symmetric keys support automatic rotation
+symmetric_key(key) {
+    not key.config.customer_master_key_spec
+}
+
+symmetric_key(key) {
+    key.config.customer_master_key_spec == "SYMMETRIC_DEFAULT"
+}
@@ -0,0 +1,175 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# RDS instances
+
+# METADATA
+# title: RDS storage encryption at rest
+# description: RDS instances must be encrypted at rest
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/Overview.Encryption.html
+# custom:
+#   check_id: KILN-RDS-001
+#   severity: critical
+#   remediation: Set 'storage_encrypted = true' on the aws_db_instance resource
+#   resource_types: [aws_db_instance]
+#   controls:
+#     soc2: [CC6.6]
+#     iso27001: [A.8.24]
+#     hipaa: [164.312(a)(2)(iv)]
+#     pci: [3.5.1]
+#     cis-aws: [2.3.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    not resource.config.storage_encrypted == true
+
+    finding := {
+        "check_id": "KILN-RDS-001",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' does not have storage encryption enabled", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    resource.config.storage_encrypted == true
+
+    finding := {
+        "check_id": "KILN-RDS-001",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' has encryption enabled", [resource.name])
+    }
+}
+
+# METADATA
+# title: RDS automated backups
+# description: RDS instances should have automated backups
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_WorkingWithAutomatedBackups.html
+# custom:
+#   check_id: KILN-RDS-002
+#   severity: high
+#   remediation: Set backup_retention_period to at least 7 days
+#   resource_types: [aws_db_instance]
+#   controls:
+#     soc2: [CC7.1]
+#     iso27001: [A.8.13]
+#     hipaa: [164.308(a)(7)(ii)(A)]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    not backups_retained(resource)
+
+    finding := {
+        "check_id": "KILN-RDS-002",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' has no automated backups", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    backups_retained(resource)
+
+    finding := {
+        "check_id": "KILN-RDS-002",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' has automated backups configured", [resource.name])
+    }
+}
+
+# METADATA
+# title: Production RDS Multi-AZ
+# description: Production RDS instances should be Multi-AZ
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/Concepts.MultiAZ.html
+# custom:
+#   check_id: KILN-RDS-003
+#   severity: medium
+#   remediation: Set multi_az = true for high availability
+#   resource_types: [aws_db_instance]
+#   controls:
+#     soc2: [CC7.1]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    not resource.config.multi_az == true
+    is_production(resource)
+
+    finding := {
+        "check_id": "KILN-RDS-003",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' is not Multi-AZ", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    resource.config.multi_az == true
+    is_production(resource)
+
+    finding := {
+        "check_id": "KILN-RDS-003",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' is Multi-AZ for high availability", [resource.name])
+    }
+}
+
+# METADATA
+# title: RDS instance not publicly accessible
+# description: RDS instances must not be publicly accessible
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_VPC.WorkingWithRDSInstanceinaVPC.html
+# custom:
+#   check_id: KILN-RDS-004
+#   severity: critical
+#   remediation: Set publicly_accessible = false
+#   resource_types: [aws_db_instance]
+#   controls:
+#     iso27001: [A.5.15]
+#     hipaa: [164.312(a)(1)]
+#     pci: [1.4.4]
+#     cis-aws: [2.3.3]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    resource.config.publicly_accessible == true
+
+    finding := {
+        "check_id": "KILN-RDS-004",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' is publicly accessible", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_db_instance"
+    not resource.config.publicly_accessible == true
+
+    finding := {
+        "check_id": "KILN-RDS-004",
+        "resource": resource.address,
+        "message": sprintf("RDS instance '%s' is not publicly accessible", [resource.name])
+    }
+}
+
+# Helper: Automated backups kept for at least a week
+backups_retained(db) {
+    to_number(db.config.backup_retention_period) >= 7
+}
+
+# Helper: Check if resource is production
+is_production(resource) {
+    resource.config.tags.Environment == "production"
+}
+
+is_production(resource) {
+    resource.config.tags.Environment == "prod"
+}
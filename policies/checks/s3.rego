@@ -0,0 +1,294 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# S3 buckets
+
+# METADATA
+# title: S3 bucket encryption at rest
+# description: S3 buckets must be encrypted at rest
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/serv-side-encryption.html
+# custom:
+#   check_id: KILN-S3-001
+#   severity: critical
+#   remediation: Add server_side_encryption_configuration block with AES256 or aws:kms
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_server_side_encryption_configuration]
+#   controls:
+#     soc2: [CC6.6]
+#     iso27001: [A.8.24]
+#     hipaa: [164.312(a)(2)(iv)]
+#     pci: [3.5.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_encrypted(resource)
+
+    finding := {
+        "check_id": "KILN-S3-001",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' does not have encryption enabled", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_encrypted(resource)
+
+    finding := {
+        "check_id": "KILN-S3-001",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has encryption enabled", [resource.name])
+    }
+}
+
+# METADATA
+# title: S3 bucket public access block
+# description: S3 buckets must block public access
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html
+# custom:
+#   check_id: KILN-S3-002
+#   severity: critical
+#   remediation: Add aws_s3_bucket_public_access_block with all settings true
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_public_access_block]
+#   controls:
+#     soc2: [CC6.1]
+#     iso27001: [A.5.15]
+#     hipaa: [164.312(a)(1)]
+#     pci: [1.4.4]
+#     cis-aws: [2.1.4]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_public_access_blocked(resource)
+
+    finding := {
+        "check_id": "KILN-S3-002",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' does not block public access", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_public_access_blocked(resource)
+
+    finding := {
+        "check_id": "KILN-S3-002",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' blocks public access", [resource.name])
+    }
+}
+
+# METADATA
+# title: S3 bucket HTTPS-only access
+# description: S3 buckets should require HTTPS
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/security-best-practices.html
+# custom:
+#   check_id: KILN-S3-003
+#   severity: medium
+#   remediation: Add aws_s3_bucket_policy requiring aws:SecureTransport
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_policy]
+#   controls:
+#     soc2: [CC6.7]
+#     hipaa: [164.312(e)(1)]
+#     pci: [4.2.1]
+#     cis-aws: [2.1.1]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_https_only(resource)
+
+    finding := {
+        "check_id": "KILN-S3-003",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' does not enforce HTTPS-only access", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_https_only(resource)
+
+    finding := {
+        "check_id": "KILN-S3-003",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' enforces HTTPS-only access", [resource.name])
+    }
+}
+
+# METADATA
+# title: S3 bucket versioning for recovery
+# description: S3 buckets should keep previous object versions for recovery
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/Versioning.html
+# custom:
+#   check_id: KILN-S3-004
+#   severity: medium
+#   remediation: Add aws_s3_bucket_versioning with status = Enabled
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_versioning]
+#   controls:
+#     soc2: [CC7.1]
+#     iso27001: [A.8.13]
+#     hipaa: [164.312(c)(1)]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_versioned(resource)
+
+    finding := {
+        "check_id": "KILN-S3-004",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has no versioning", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_versioned(resource)
+
+    finding := {
+        "check_id": "KILN-S3-004",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has versioning enabled", [resource.name])
+    }
+}
+
+# METADATA
+# title: S3 bucket access logging
+# description: S3 buckets should have access logging
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/ServerLogs.html
+# custom:
+#   check_id: KILN-S3-005
+#   severity: medium
+#   remediation: Add aws_s3_bucket_logging resource
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_logging]
+#   controls:
+#     soc2: [CC7.2]
+#     iso27001: [A.8.15]
+#     hipaa: [164.312(b)]
+#     pci: [10.2.1]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_logged(resource)
+
+    finding := {
+        "check_id": "KILN-S3-005",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has no access logging", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_logged(resource)
+
+    finding := {
+        "check_id": "KILN-S3-005",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has access logging enabled", [resource.name])
+    }
+}
+
+# METADATA
+# title: S3 bucket versioning for change tracking
+# description: S3 buckets should manage versioning as its own resource so changes to it
+#   are tracked
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/Versioning.html
+# custom:
+#   check_id: KILN-S3-006
+#   severity: low
+#   remediation: Enable versioning for change tracking
+#   resource_types: [aws_s3_bucket, aws_s3_bucket_versioning]
+#   controls:
+#     soc2: [CC8.1]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    not bucket_versioning_resource(resource)
+
+    finding := {
+        "check_id": "KILN-S3-006",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' should enable versioning", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_s3_bucket"
+    bucket_versioning_resource(resource)
+
+    finding := {
+        "check_id": "KILN-S3-006",
+        "resource": resource.address,
+        "message": sprintf("S3 bucket '%s' has versioning for change tracking", [resource.name])
+    }
+}
+
+# Helper: Encryption inline or with a separate configuration resource
+bucket_encrypted(bucket) {
+    bucket.config.server_side_encryption_configuration
+}
+
+bucket_encrypted(bucket) {
+    encryption := input.resources[_]
+    encryption.type == "aws_s3_bucket_server_side_encryption_configuration"
+    references(encryption.config.bucket, bucket)
+}
+
+# Helper: Every public access setting blocked
+bucket_public_access_blocked(bucket) {
+    block := input.resources[_]
+    block.type == "aws_s3_bucket_public_access_block"
+    references(block.config.bucket, bucket)
+    block.config.block_public_acls == true
+    block.config.block_public_policy == true
+    block.config.ignore_public_acls == true
+    block.config.restrict_public_buckets == true
+}
+
+# Helper: Bucket policy requiring aws:SecureTransport
+bucket_https_only(bucket) {
+    policy := input.resources[_]
+    policy.type == "aws_s3_bucket_policy"
+    references(policy.config.bucket, bucket)
+    contains(policy.config.policy, "aws:SecureTransport")
+}
+
+# Helper: Versioning inline or with a separate resource
+bucket_versioned(bucket) {
+    bucket_versioning_resource(bucket)
+}
+
+bucket_versioned(bucket) {
+    blocks(bucket.config.versioning)[_].enabled == true
+}
+
+bucket_versioning_resource(bucket) {
+    versioning := input.resources[_]
+    versioning.type == "aws_s3_bucket_versioning"
+    references(versioning.config.bucket, bucket)
+}
+
+# Helper: Server access logging
+bucket_logged(bucket) {
+    logging := input.resources[_]
+    logging.type == "aws_s3_bucket_logging"
+    references(logging.config.bucket, bucket)
+}
+
+bucket_logged(bucket) {
+    bucket.config.logging
+}
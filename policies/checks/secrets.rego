@@ -0,0 +1,160 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# Sensitive data in outputs and variables
+
+# METADATA
+# title: Secret outputs marked sensitive
+# description: Outputs exposing secrets must be marked sensitive
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/values/outputs
+# custom:
+#   check_id: KILN-SECRET-001
+#   severity: high
+#   remediation: Set sensitive = true on the output, or stop exporting the secret
+#   resource_types: [output]
+#   controls:
+#     soc2: [CC6.1]
+violations[finding] {
+    output := input.outputs[name]
+    exposes_secret(output)
+    not output.sensitive == true
+
+    finding := {
+        "check_id": "KILN-SECRET-001",
+        "resource": sprintf("output.%s", [name]),
+        "message": sprintf("Output '%s' exposes a secret without sensitive = true", [name])
+    }
+}
+
+passed[finding] {
+    output := input.outputs[name]
+    exposes_secret(output)
+    output.sensitive == true
+
+    finding := {
+        "check_id": "KILN-SECRET-001",
+        "resource": sprintf("output.%s", [name]),
+        "message": sprintf("Output '%s' is marked sensitive", [name])
+    }
+}
+
+# METADATA
+# title: No credentials in output connection strings
+# description: Outputs must not expose connection strings with embedded credentials
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/values/outputs
+# custom:
+#   check_id: KILN-SECRET-002
+#   severity: critical
+#   remediation: Remove credentials from the connection string and mark the output
+#     sensitive = true
+#   resource_types: [output]
+#   controls:
+#     soc2: [CC6.1]
+violations[finding] {
+    output := input.outputs[name]
+    embeds_credentials(output)
+    not output.sensitive == true
+
+    finding := {
+        "check_id": "KILN-SECRET-002",
+        "resource": sprintf("output.%s", [name]),
+        "message": sprintf("Output '%s' exposes a connection string with embedded credentials", [name])
+    }
+}
+
+# METADATA
+# title: No hard-coded credential defaults
+# description: Variables must not hard-code credentials as defaults
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/values/variables
+# custom:
+#   check_id: KILN-SECRET-003
+#   severity: critical
+#   remediation: Remove the default and supply the value from a secrets manager or
+#     TF_VAR_ environment variable
+#   resource_types: [variable]
+#   controls:
+#     soc2: [CC6.1]
+violations[finding] {
+    variable := input.variables[name]
+    secret_name(name)
+    has_string_default(variable)
+
+    finding := {
+        "check_id": "KILN-SECRET-003",
+        "resource": sprintf("var.%s", [name]),
+        "message": sprintf("Variable '%s' has a hard-coded credential as its default", [name])
+    }
+}
+
+passed[finding] {
+    variable := input.variables[name]
+    secret_name(name)
+    variable.sensitive == true
+    not has_string_default(variable)
+
+    finding := {
+        "check_id": "KILN-SECRET-003",
+        "resource": sprintf("var.%s", [name]),
+        "message": sprintf("Variable '%s' is sensitive with no hard-coded default", [name])
+    }
+}
+
+# METADATA
+# title: Credential variables marked sensitive
+# description: Credential variables should be marked sensitive
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/values/variables
+# custom:
+#   check_id: KILN-SECRET-004
+#   severity: medium
+#   remediation: Set sensitive = true on the variable so its value is redacted from plan
+#     output
+#   resource_types: [variable]
+#   controls:
+#     soc2: [CC6.1]
+warnings[finding] {
+    variable := input.variables[name]
+    secret_name(name)
+    not variable.sensitive == true
+
+    finding := {
+        "check_id": "KILN-SECRET-004",
+        "resource": sprintf("var.%s", [name]),
+        "message": sprintf("Variable '%s' holds a credential but is not marked sensitive", [name])
+    }
+}
+
+# Helper: Names that suggest a credential, excluding identifiers such as
+# secret_arn or token_name that are safe to expose
+secret_name(name) {
+    regex.match(`(?i)(password|passwd|secret|token|api_?key|private_key|access_key|credential|connection_string)`, name)
+    not regex.match(`(?i)(_arn|_id|_ids|_name|_version|_length|_rotation_days)$`, name)
+}
+
+# Helper: Output is named like a secret or returns a secret attribute
+exposes_secret(output) {
+    secret_name(output.name)
+}
+
+exposes_secret(output) {
+    regex.match(`(?i)\.(password|master_password|secret|secret_string|secret_access_key|token|auth_token|private_key|private_key_pem|connection_string)\b`, output.expression)
+}
+
+# Helper: Connection string with user:password@host
+embeds_credentials(output) {
+    regex.match(`[a-z][a-z0-9+.-]*://[^/\s:@]+:[^@\s]+@`, output.value)
+}
+
+embeds_credentials(output) {
+    regex.match(`[a-z][a-z0-9+.-]*://[^/\s:@]+:[^@\s]+@`, output.expression)
+}
+
+has_string_default(variable) {
+    is_string(variable.default)
+    count(variable.default) > 0
+}
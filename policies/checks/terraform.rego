@@ -0,0 +1,164 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# Tagging and Terraform state
+
+# METADATA
+# title: Resource ownership tags
+# description: Resources should carry ownership tags
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/whitepapers/latest/tagging-best-practices/tagging-best-practices.html
+# custom:
+#   check_id: KILN-TAG-001
+#   severity: medium
+#   remediation: "Add the required tags (Environment and Owner unless configured otherwise)"
+#   resource_types: [aws_s3_bucket, aws_db_instance, aws_instance, aws_vpc, aws_subnet, aws_security_group, aws_lb]
+#   controls:
+#     soc2: [CC8.1]
+violations[finding] {
+    resource := input.resources[_]
+    taggable_resource(resource.type)
+    not has_required_tags(resource)
+
+    finding := {
+        "check_id": "KILN-TAG-001",
+        "resource": resource.address,
+        "message": sprintf("Resource '%s' is missing required tags", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    taggable_resource(resource.type)
+    has_required_tags(resource)
+
+    finding := {
+        "check_id": "KILN-TAG-001",
+        "resource": resource.address,
+        "message": sprintf("Resource '%s' has required tags", [resource.name])
+    }
+}
+
+# METADATA
+# title: Terraform state encrypted
+# description: Remote state in S3 must be encrypted
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/backend/s3
+# custom:
+#   check_id: KILN-STATE-001
+#   severity: high
+#   remediation: Set encrypt = true in the s3 backend block
+#   resource_types: [terraform_backend]
+#   controls:
+#     soc2: [CC8.1]
+violations[finding] {
+    backend := input.backends[_]
+    backend.type == "s3"
+    not backend.config.encrypt == true
+
+    finding := {
+        "check_id": "KILN-STATE-001",
+        "resource": "terraform.backend.s3",
+        "message": "S3 state backend does not encrypt Terraform state"
+    }
+}
+
+passed[finding] {
+    backend := input.backends[_]
+    backend.type == "s3"
+    backend.config.encrypt == true
+    has_state_locking(backend)
+
+    finding := {
+        "check_id": "KILN-STATE-001",
+        "resource": "terraform.backend.s3",
+        "message": "S3 state backend is encrypted with state locking"
+    }
+}
+
+# METADATA
+# title: Terraform state locking
+# description: Remote state in S3 should be locked against concurrent changes
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/backend/s3
+# custom:
+#   check_id: KILN-STATE-002
+#   severity: medium
+#   remediation: Set dynamodb_table (or use_lockfile = true) in the s3 backend block
+#   resource_types: [terraform_backend]
+#   controls:
+#     soc2: [CC8.1]
+warnings[finding] {
+    backend := input.backends[_]
+    backend.type == "s3"
+    not has_state_locking(backend)
+
+    finding := {
+        "check_id": "KILN-STATE-002",
+        "resource": "terraform.backend.s3",
+        "message": "S3 state backend has no state locking"
+    }
+}
+
+# METADATA
+# title: Remote Terraform state
+# description: Local state can't be shared, reviewed or audited
+# related_resources:
+#   - ref: https://developer.hashicorp.com/terraform/language/backend
+# custom:
+#   check_id: KILN-STATE-003
+#   severity: medium
+#   remediation: Use a remote backend (e.g. s3 with encryption and locking) so changes go
+#     through a shared, audited state
+#   resource_types: [terraform_backend]
+#   controls:
+#     soc2: [CC8.1]
+warnings[finding] {
+    backend := input.backends[_]
+    backend.type == "local"
+
+    finding := {
+        "check_id": "KILN-STATE-003",
+        "resource": "terraform.backend.local",
+        "message": "Terraform state is stored locally"
+    }
+}
+
+# Helper: Check for state locking
+has_state_locking(backend) {
+    backend.config.dynamodb_table
+}
+
+has_state_locking(backend) {
+    backend.config.use_lockfile == true
+}
+
+# Helper: Resources that should be tagged
+taggable_resource(resource_type) {
+    taggable_types := [
+        "aws_s3_bucket",
+        "aws_db_instance",
+        "aws_instance",
+        "aws_vpc",
+        "aws_subnet",
+        "aws_security_group",
+        "aws_lb"
+    ]
+    taggable_types[_] == resource_type
+}
+
+# Helper: Tags every taggable resource must carry, set with required_tags
+# in .kiln.yaml
+default_required_tags := ["Environment", "Owner"]
+
+required_tags = tags {
+    tags := input.config.required_tags
+} else = default_required_tags
+
+# Helper: Check for required tags
+has_required_tags(resource) {
+    missing := {tag | tag := required_tags[_]; not resource.config.tags[tag]}
+    count(missing) == 0
+}
@@ -0,0 +1,275 @@
+# Copyright 2025 Kiln
+# Licensed under the Apache License, Version 2.0
+
+package checks
+
+# VPCs and security groups
+
+# METADATA
+# title: Security group sensitive ports closed to the internet
+# description: Security groups must not open sensitive ports to the internet
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/vpc-security-groups.html
+# custom:
+#   check_id: KILN-VPC-001
+#   severity: critical
+#   remediation: Restrict ingress to specific IP ranges
+#   resource_types: [aws_security_group]
+#   controls:
+#     soc2: [CC6.1]
+#     iso27001: [A.8.20]
+#     hipaa: [164.312(a)(1)]
+#     pci: [1.3.1]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_security_group"
+    has_unrestricted_ingress(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-001",
+        "resource": resource.address,
+        "message": sprintf("Security group '%s' allows unrestricted access to sensitive ports", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_security_group"
+    not has_unrestricted_ingress(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-001",
+        "resource": resource.address,
+        "message": sprintf("Security group '%s' has restricted access controls", [resource.name])
+    }
+}
+
+# METADATA
+# title: Security group SSH/RDP closed to 0.0.0.0/0
+# description: Security groups must not allow SSH or RDP from 0.0.0.0/0
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/vpc-security-groups.html
+# custom:
+#   check_id: KILN-VPC-002
+#   severity: high
+#   remediation: Restrict ports 22 and 3389 to known IP ranges, or use SSM Session
+#     Manager
+#   resource_types: [aws_security_group]
+#   controls:
+#     cis-aws: ["5.2"]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_security_group"
+    ingress := blocks(resource.config.ingress)[_]
+    ingress.cidr_blocks[_] == "0.0.0.0/0"
+    port_in_range(ingress, admin_ports)
+
+    finding := {
+        "check_id": "KILN-VPC-002",
+        "resource": resource.address,
+        "message": sprintf("Security group '%s' allows SSH or RDP from 0.0.0.0/0", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_security_group"
+    not world_ingress(resource, admin_ports)
+
+    finding := {
+        "check_id": "KILN-VPC-002",
+        "resource": resource.address,
+        "message": sprintf("Security group '%s' does not expose remote administration ports", [resource.name])
+    }
+}
+
+# METADATA
+# title: Security group SSH/RDP closed to ::/0
+# description: Security groups must not allow SSH or RDP from ::/0
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/vpc-security-groups.html
+# custom:
+#   check_id: KILN-VPC-003
+#   severity: high
+#   remediation: Restrict ports 22 and 3389 to known IP ranges, or use SSM Session
+#     Manager
+#   resource_types: [aws_security_group]
+#   controls:
+#     cis-aws: ["5.3"]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_security_group"
+    ingress := blocks(resource.config.ingress)[_]
+    ingress.ipv6_cidr_blocks[_] == "::/0"
+    port_in_range(ingress, admin_ports)
+
+    finding := {
+        "check_id": "KILN-VPC-003",
+        "resource": resource.address,
+        "message": sprintf("Security group '%s' allows SSH or RDP from ::/0", [resource.name])
+    }
+}
+
+# METADATA
+# title: Default security group restricts all traffic
+# description: The default security group must restrict all traffic
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/default-security-group.html
+# custom:
+#   check_id: KILN-VPC-004
+#   severity: medium
+#   remediation: Remove all ingress and egress rules from aws_default_security_group
+#   resource_types: [aws_default_security_group]
+#   controls:
+#     cis-aws: ["5.4"]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_default_security_group"
+    default_group_allows_traffic(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-004",
+        "resource": resource.address,
+        "message": "Default security group allows traffic"
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_default_security_group"
+    not default_group_allows_traffic(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-004",
+        "resource": resource.address,
+        "message": "Default security group restricts all traffic"
+    }
+}
+
+# METADATA
+# title: VPC default security group managed
+# description: VPCs without a managed default security group keep AWS's permissive
+#   default
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/default-security-group.html
+# custom:
+#   check_id: KILN-VPC-005
+#   severity: low
+#   remediation: Add aws_default_security_group for the VPC with no rules
+#   resource_types: [aws_vpc, aws_default_security_group]
+#   controls:
+#     cis-aws: ["5.4"]
+warnings[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_vpc"
+    not manages_default_group(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-005",
+        "resource": resource.address,
+        "message": sprintf("VPC '%s' leaves its default security group unrestricted", [resource.name])
+    }
+}
+
+# METADATA
+# title: VPC flow logs
+# description: VPCs should have flow logs
+# related_resources:
+#   - ref: https://docs.aws.amazon.com/vpc/latest/userguide/flow-logs.html
+# custom:
+#   check_id: KILN-VPC-006
+#   severity: high
+#   remediation: Add aws_flow_log resource
+#   resource_types: [aws_vpc, aws_flow_log]
+#   controls:
+#     soc2: [CC7.2]
+#     iso27001: [A.8.16]
+#     hipaa: [164.312(b)]
+#     pci: [10.2.1]
+#     cis-aws: ["3.7"]
+violations[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_vpc"
+    not vpc_flow_logs(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-006",
+        "resource": resource.address,
+        "message": sprintf("VPC '%s' has no flow logs", [resource.name])
+    }
+}
+
+passed[finding] {
+    resource := input.resources[_]
+    resource.type == "aws_vpc"
+    vpc_flow_logs(resource)
+
+    finding := {
+        "check_id": "KILN-VPC-006",
+        "resource": resource.address,
+        "message": sprintf("VPC '%s' has flow logs enabled", [resource.name])
+    }
+}
+
+# Helper: Sensitive port open to 0.0.0.0/0
+has_unrestricted_ingress(sg) {
+    ingress := blocks(sg.config.ingress)[_]
+    ingress.cidr_blocks[_] == "0.0.0.0/0"
+    sensitive_ports[_] == ingress.from_port
+}
+
+# Ports of databases, caches and remote administration
+sensitive_ports := [22, 3389, 1433, 3306, 5432, 6379, 27017]
+
+# Ports of remote administration only
+admin_ports := [22, 3389]
+
+# Helper: Security group admitting the internet to one of ports
+world_ingress(sg, ports) {
+    ingress := blocks(sg.config.ingress)[_]
+    world_cidr(ingress)
+    port_in_range(ingress, ports)
+}
+
+world_cidr(rule) {
+    rule.cidr_blocks[_] == "0.0.0.0/0"
+}
+
+world_cidr(rule) {
+    rule.ipv6_cidr_blocks[_] == "::/0"
+}
+
+port_in_range(rule, ports) {
+    port := ports[_]
+    rule.from_port <= port
+    rule.to_port >= port
+}
+
+port_in_range(rule, ports) {
+    # Protocol -1 opens every port
+    rule.protocol == "-1"
+    count(ports) > 0
+}
+
+# Helper: Check for rules on a default security group
+default_group_allows_traffic(sg) {
+    count(blocks(sg.config.ingress)) > 0
+}
+
+default_group_allows_traffic(sg) {
+    count(blocks(sg.config.egress)) > 0
+}
+
+# Helper: Check whether a VPC's default security group is managed
+manages_default_group(vpc) {
+    sg := input.resources[_]
+    sg.type == "aws_default_security_group"
+    references(sg.config.vpc_id, vpc)
+}
+
+# Helper: Check if VPC has flow logs
+vpc_flow_logs(vpc) {
+    flow_log := input.resources[_]
+    flow_log.type == "aws_flow_log"
+    references(flow_log.config.vpc_id, vpc)
+}
@@ -0,0 +1,12 @@
+// Package policies embeds the Rego checks and control catalog shipped with
+// kiln, so the binary works from any directory.
+package policies
+
+import "embed"
+
+// FS holds the checks/ directory: one Rego package of checks, each with a
+// stable check ID and a METADATA annotation mapping it to framework
+// controls, and controls.yaml describing the controls
+//
+//go:embed */*.rego */*.yaml
+var FS embed.FS
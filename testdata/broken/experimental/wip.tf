@@ -0,0 +1,5 @@
+# Work in progress: unterminated block
+
+resource "aws_db_instance" "experiment" {
+  engine            = "postgres"
+  storage_encrypted = false
@@ -0,0 +1,25 @@
+# Scanned normally even though files elsewhere in this tree are broken
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "acme-assets"
+
+  tags = {
+    Environment = "production"
+    Owner       = "web"
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "assets" {
+  bucket = aws_s3_bucket.assets.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+
+# A resource block missing its name label is reported and skipped
+resource "aws_s3_bucket" {
+  bucket = "acme-unnamed"
+}
@@ -0,0 +1,87 @@
+# Configuration for the deployment recorded in terraform.tfstate. The state
+# has drifted: RDS encryption was turned off, the data bucket's default
+# encryption was switched from KMS to AES256, SSH was opened on the web
+# security group from the console, and a bucket and rule were created by hand.
+
+resource "aws_s3_bucket" "data" {
+  bucket = "acme-prod-data"
+
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default {
+        sse_algorithm = "aws:kms"
+      }
+    }
+  }
+
+  tags = {
+    Environment = "production"
+    Owner       = "data-platform"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "data" {
+  bucket = aws_s3_bucket.data.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_versioning" "data" {
+  bucket = aws_s3_bucket.data.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_security_group" "web" {
+  name        = "acme-prod-web"
+  description = "Web tier"
+  vpc_id      = "vpc-0123456789abcdef0"
+
+  ingress {
+    description = "HTTPS"
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  tags = {
+    Environment = "production"
+    Owner       = "web"
+  }
+}
+
+resource "aws_db_instance" "main" {
+  identifier              = "acme-prod"
+  engine                  = "postgres"
+  engine_version          = "15.5"
+  instance_class          = "db.t3.medium"
+  allocated_storage       = 100
+  username                = "app"
+  password                = var.db_password
+  storage_encrypted       = true
+  backup_retention_period = 7
+  deletion_protection     = true
+
+  tags = {
+    Environment = "production"
+    Owner       = "data-platform"
+  }
+}
+
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
@@ -0,0 +1,66 @@
+# Resources repeated with count, for_each and dynamic blocks
+
+variable "buckets" {
+  type = map(object({
+    encrypted = bool
+  }))
+  default = {
+    logs    = { encrypted = true }
+    exports = { encrypted = false }
+  }
+}
+
+variable "admin_ports" {
+  type    = list(number)
+  default = [22, 443]
+}
+
+resource "aws_s3_bucket" "fleet" {
+  for_each = var.buckets
+
+  bucket = "acme-${each.key}"
+
+  tags = {
+    Environment = "production"
+    Owner       = "data-team"
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "fleet" {
+  for_each = { for name, cfg in var.buckets : name => cfg if cfg.encrypted }
+
+  bucket = aws_s3_bucket.fleet[each.key].id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "aws:kms"
+    }
+  }
+}
+
+resource "aws_ebs_volume" "data" {
+  count = 2
+
+  availability_zone = "us-east-1a"
+  size              = 100
+  encrypted         = count.index == 0
+}
+
+resource "aws_security_group" "admin" {
+  name = "admin"
+
+  dynamic "ingress" {
+    for_each = var.admin_ports
+    content {
+      from_port   = ingress.value
+      to_port     = ingress.value
+      protocol    = "tcp"
+      cidr_blocks = ["0.0.0.0/0"]
+    }
+  }
+
+  tags = {
+    Environment = "production"
+    Owner       = "network-team"
+  }
+}
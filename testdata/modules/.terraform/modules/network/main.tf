@@ -0,0 +1,16 @@
+variable "cidr" {
+  type = string
+}
+
+variable "environment" {
+  type = string
+}
+
+resource "aws_vpc" "this" {
+  cidr_block = var.cidr
+
+  tags = {
+    Environment = var.environment
+    Owner       = "network-team"
+  }
+}
@@ -0,0 +1,31 @@
+# Root module calling a local module and a registry module resolved by
+# terraform init into .terraform/modules
+
+variable "environment" {
+  type    = string
+  default = "production"
+}
+
+module "logs" {
+  source = "./modules/bucket"
+
+  name        = "app-logs"
+  encrypted   = true
+  environment = var.environment
+}
+
+module "uploads" {
+  source = "./modules/bucket"
+
+  name        = "app-uploads"
+  encrypted   = false
+  environment = var.environment
+}
+
+module "network" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.8.1"
+
+  cidr        = "10.0.0.0/16"
+  environment = var.environment
+}
@@ -0,0 +1,55 @@
+# Secrets leaking through outputs and variable defaults
+
+variable "db_username" {
+  type        = string
+  description = "Database admin user"
+  default     = "admin"
+}
+
+variable "db_password" {
+  type        = string
+  description = "Database admin password"
+  default     = "SuperSecret123!"
+}
+
+variable "api_token" {
+  type      = string
+  sensitive = true
+}
+
+variable "secret_arn" {
+  type    = string
+  default = "arn:aws:secretsmanager:us-east-1:123456789012:secret:app"
+}
+
+resource "aws_db_instance" "main" {
+  identifier              = "app-db"
+  engine                  = "postgres"
+  username                = var.db_username
+  password                = var.db_password
+  storage_encrypted       = true
+  backup_retention_period = 7
+
+  tags = {
+    Environment = "production"
+    Owner       = "platform-team"
+  }
+}
+
+output "db_endpoint" {
+  description = "Database endpoint"
+  value       = aws_db_instance.main.endpoint
+}
+
+output "db_password" {
+  value = aws_db_instance.main.password
+}
+
+output "db_url" {
+  value = "postgres://${var.db_username}:${var.db_password}@${aws_db_instance.main.endpoint}/app"
+}
+
+output "admin_password" {
+  value     = var.db_password
+  sensitive = true
+}
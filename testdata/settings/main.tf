@@ -0,0 +1,58 @@
+# Terraform settings, providers and data sources
+
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+
+  backend "s3" {
+    bucket = "acme-terraform-state"
+    key    = "platform/terraform.tfstate"
+    region = "us-east-1"
+  }
+}
+
+locals {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  region = local.region
+}
+
+provider "aws" {
+  alias  = "dr"
+  region = "us-west-2"
+}
+
+data "aws_iam_policy_document" "admin" {
+  statement {
+    actions   = ["*"]
+    resources = ["*"]
+  }
+}
+
+data "aws_iam_policy_document" "read_logs" {
+  statement {
+    effect    = "Allow"
+    actions   = ["s3:GetObject", "s3:ListBucket"]
+    resources = ["arn:aws:s3:::acme-logs", "arn:aws:s3:::acme-logs/*"]
+  }
+
+  statement {
+    effect    = "Deny"
+    actions   = ["*"]
+    resources = ["*"]
+
+    condition {
+      test     = "Bool"
+      variable = "aws:SecureTransport"
+      values   = ["false"]
+    }
+  }
+}
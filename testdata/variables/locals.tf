@@ -0,0 +1,10 @@
+locals {
+  common_tags = {
+    Environment = var.environment
+    Owner       = var.owner
+  }
+
+  db_tags = merge(local.common_tags, {
+    Name = "app-db-${var.environment}"
+  })
+}
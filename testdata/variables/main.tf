@@ -0,0 +1,10 @@
+resource "aws_db_instance" "app" {
+  identifier              = "app-db-${var.environment}"
+  engine                  = "postgres"
+  instance_class          = "db.t3.medium"
+  storage_encrypted       = var.encrypt_storage
+  backup_retention_period = var.backup_retention_days
+  multi_az                = var.environment == "production"
+
+  tags = local.db_tags
+}
@@ -0,0 +1,19 @@
+variable "environment" {
+  type        = string
+  description = "Deployment environment"
+  default     = "staging"
+}
+
+variable "encrypt_storage" {
+  type        = bool
+  description = "Whether to encrypt database storage"
+}
+
+variable "backup_retention_days" {
+  type    = number
+  default = 1
+}
+
+variable "owner" {
+  type = string
+}